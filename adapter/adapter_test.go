@@ -0,0 +1,257 @@
+package adapter_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/gin-gonic/gin"
+	"github.com/go-chi/chi/v5"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gorilla/mux"
+	"github.com/its-felix/aws-lambda-go-http-adapter/adapter"
+	"github.com/its-felix/aws-lambda-go-http-adapter/adaptertest"
+	"github.com/its-felix/aws-lambda-go-http-adapter/handler"
+	"github.com/its-felix/aws-lambda-go-http-adapter/handlertest"
+	"github.com/labstack/echo/v4"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+func TestVanillaAdapter(t *testing.T) {
+	adaptertest.Run(t, adapter.NewVanillaAdapter)
+}
+
+func TestEchoAdapter(t *testing.T) {
+	adaptertest.Run(t, func(h http.Handler) handler.AdapterFunc {
+		app := echo.New()
+		app.Any("/*", echo.WrapHandler(h))
+
+		return adapter.NewEchoAdapter(app)
+	})
+}
+
+func TestGinAdapter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	adaptertest.Run(t, func(h http.Handler) handler.AdapterFunc {
+		app := gin.New()
+		app.Any("/*path", gin.WrapH(h))
+
+		return adapter.NewGinAdapter(app)
+	})
+}
+
+func TestGetSourceEventGin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var event any
+	app := gin.New()
+	app.GET("/ping", func(c *gin.Context) {
+		event = adapter.GetSourceEventGin(c)
+		c.String(http.StatusOK, "pong")
+	})
+
+	source := handlertest.FunctionURL().GET("/ping").Build()
+	res, err := handler.NewFunctionURLHandler(adapter.NewGinAdapter(app))(context.Background(), source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handlertest.MustNewResponse(t, res).AssertBody(t, "pong")
+
+	if _, ok := event.(events.LambdaFunctionURLRequest); !ok {
+		t.Errorf("expected the source event to be available, got %T", event)
+	}
+}
+
+func TestGinStreaming(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	next := make(chan struct{})
+	app := gin.New()
+	app.GET("/stream", func(c *gin.Context) {
+		i := 0
+		c.Stream(func(w io.Writer) bool {
+			i++
+			_, _ = fmt.Fprintf(w, "chunk %d\n", i)
+
+			if i == 1 {
+				return true
+			}
+
+			<-next
+			return false
+		})
+	})
+
+	res, err := handler.NewFunctionURLStreamingHandler(adapter.NewGinAdapter(app))(context.Background(), handlertest.FunctionURL().GET("/stream").Build())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := bufio.NewReader(res.Body)
+	if line, err := r.ReadString('\n'); err != nil || line != "chunk 1\n" {
+		t.Fatalf("expected the first chunk to arrive before the stream ended, got %q (%v)", line, err)
+	}
+
+	close(next)
+
+	if rest, _ := io.ReadAll(r); string(rest) != "chunk 2\n" {
+		t.Errorf("unexpected remaining body: %q", rest)
+	}
+}
+
+func TestWithChiURLParams(t *testing.T) {
+	var id string
+	h := adapter.WithChiURLParams(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id = chi.URLParam(r, "id")
+	}))
+
+	event := handlertest.APIGatewayV2().GET("/users/123").Modify(func(event *events.APIGatewayV2HTTPRequest) {
+		event.RouteKey = "GET /users/{id}"
+		event.PathParameters = map[string]string{"id": "123"}
+	}).Build()
+
+	if _, err := handler.NewAPIGatewayV2Handler(adapter.NewVanillaAdapter(h))(context.Background(), event); err != nil {
+		t.Fatal(err)
+	}
+
+	if id != "123" {
+		t.Errorf("expected the path parameter to be available, got %q", id)
+	}
+}
+
+func TestWithGorillaMuxVars(t *testing.T) {
+	var vars map[string]string
+	h := adapter.WithGorillaMuxVars(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vars = mux.Vars(r)
+	}))
+
+	event := handlertest.APIGatewayV1().GET("/users/123").Modify(func(event *events.APIGatewayProxyRequest) {
+		event.Resource = "/users/{id}"
+		event.PathParameters = map[string]string{"id": "123"}
+	}).Build()
+
+	if _, err := handler.NewAPIGatewayV1Handler(adapter.NewVanillaAdapter(h))(context.Background(), event); err != nil {
+		t.Fatal(err)
+	}
+
+	if vars["id"] != "123" {
+		t.Errorf("expected the path parameter to be available, got %v", vars)
+	}
+}
+
+func TestFiberAdapter(t *testing.T) {
+	adaptertest.Run(t, func(h http.Handler) handler.AdapterFunc {
+		app := fiber.New()
+		app.All("*", newFiberBridge(h))
+
+		return adapter.NewFiberAdapter(app)
+	})
+}
+
+// fiberBridgeWriter passes everything written by the http.Handler through a pipe into the body stream of the fiber response
+type fiberBridgeWriter struct {
+	header     http.Header
+	statusCode int
+	body       *io.PipeWriter
+	once       sync.Once
+	ready      chan struct{}
+}
+
+func (w *fiberBridgeWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *fiberBridgeWriter) Write(p []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.body.Write(p)
+}
+
+func (w *fiberBridgeWriter) WriteHeader(statusCode int) {
+	w.once.Do(func() {
+		w.statusCode = statusCode
+		w.header = w.header.Clone()
+		close(w.ready)
+	})
+}
+
+func (w *fiberBridgeWriter) Flush() {
+	w.WriteHeader(http.StatusOK)
+}
+
+// newFiberBridge creates a fiber handler which passes the request to a http.Handler, like echo.WrapHandler does for echo
+func newFiberBridge(h http.Handler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		r, err := http.NewRequestWithContext(adapter.GetContextFiber(c), c.Method(), c.OriginalURL(), bytes.NewReader(c.Body()))
+		if err != nil {
+			return err
+		}
+
+		r.Host = string(c.Request().Host())
+		r.RemoteAddr = c.Context().RemoteAddr().String()
+		r.RequestURI = c.OriginalURL()
+		c.Request().Header.VisitAll(func(key, value []byte) {
+			r.Header.Add(string(key), string(value))
+		})
+
+		pr, pw := io.Pipe()
+		w := &fiberBridgeWriter{
+			header: make(http.Header),
+			body:   pw,
+			ready:  make(chan struct{}),
+		}
+
+		panicCh := make(chan any, 1)
+		go func() {
+			defer func() {
+				if v := recover(); v != nil {
+					panicCh <- v
+				}
+
+				w.WriteHeader(http.StatusOK)
+				_ = pw.Close()
+			}()
+
+			h.ServeHTTP(w, r)
+		}()
+
+		<-w.ready
+
+		select {
+		case v := <-panicCh:
+			panic(v)
+		default:
+		}
+
+		for k, values := range w.header {
+			for _, v := range values {
+				c.Response().Header.Add(k, v)
+			}
+		}
+
+		c.Status(w.statusCode)
+		c.Context().SetBodyStreamWriter(func(bw *bufio.Writer) {
+			defer pr.Close()
+
+			buf := make([]byte, 4096)
+			for {
+				n, err := pr.Read(buf)
+				if n > 0 {
+					_, _ = bw.Write(buf[:n])
+					_ = bw.Flush()
+				}
+
+				if err != nil {
+					return
+				}
+			}
+		})
+
+		return nil
+	}
+}
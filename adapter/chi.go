@@ -0,0 +1,33 @@
+//go:build !lambdahttpadapter.partial || (lambdahttpadapter.partial && lambdahttpadapter.chi)
+
+package adapter
+
+import (
+	"context"
+	"github.com/go-chi/chi/v5"
+	"github.com/its-felix/aws-lambda-go-http-adapter/handler"
+	"net/http"
+)
+
+// WithChiURLParams makes the path parameters matched by API Gateway available using chi.URLParam
+func WithChiURLParams(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route, ok := handler.GetRoute(r.Context())
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rctx := chi.RouteContext(r.Context())
+		if rctx == nil {
+			rctx = chi.NewRouteContext()
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+		}
+
+		for k, v := range route.PathParameters {
+			rctx.URLParams.Add(k, v)
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
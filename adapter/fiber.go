@@ -11,7 +11,6 @@ import (
 	"io"
 	"net"
 	"net/http"
-	"strings"
 )
 
 const contextUserValueKey = "github.com/its-felix/aws-lambda-go-http-adapter/adapter/fiber::contextUserValueKey"
@@ -28,7 +27,13 @@ func (a fiberAdapter) adapterFunc(ctx context.Context, r *http.Request, w http.R
 	// protocol, method, uri, host
 	httpReq.Header.SetProtocol(r.Proto)
 	httpReq.Header.SetMethod(r.Method)
-	httpReq.SetRequestURI(r.URL.Scheme + "://" + r.RequestURI)
+	requestURI := r.RequestURI
+	if requestURI == "" {
+		requestURI = r.URL.RequestURI()
+	}
+
+	httpReq.SetRequestURI(requestURI)
+	httpReq.URI().SetScheme(r.URL.Scheme)
 	httpReq.SetHost(r.Host)
 
 	// body
@@ -75,16 +80,51 @@ func (a fiberAdapter) adapterFunc(ctx context.Context, r *http.Request, w http.R
 	fctx.Response.Header.VisitAll(func(key, value []byte) {
 		k := utils.UnsafeString(key)
 
-		for _, v := range strings.Split(utils.UnsafeString(value), ",") {
-			w.Header().Add(k, v)
+		// the body is never sent chunk-encoded by the adapter
+		if k == fiber.HeaderTransferEncoding {
+			return
 		}
+
+		// every value is visited separately, values may contain commas (for example Set-Cookie or Date)
+		w.Header().Add(k, string(value))
 	})
 
 	w.WriteHeader(fctx.Response.StatusCode())
+
 	// release handled in defer
-	err = fctx.Response.BodyWriteTo(w)
+	if fctx.Response.IsBodyStream() {
+		return writeBodyStream(w, &fctx.Response)
+	}
+
+	return fctx.Response.BodyWriteTo(w)
+}
+
+// writeBodyStream passes a body set using SetBodyStream or SetBodyStreamWriter to w chunk by chunk, flushing after every chunk
+func writeBodyStream(w http.ResponseWriter, res *fasthttp.Response) error {
+	defer res.CloseBodyStream()
+
+	flusher, _ := w.(http.Flusher)
+	body := res.BodyStream()
+	buf := make([]byte, 4096)
 
-	return err
+	for {
+		n, err := body.Read(buf)
+		if n > 0 {
+			if _, wErr := w.Write(buf[:n]); wErr != nil {
+				return wErr
+			}
+
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
 }
 
 func NewFiberAdapter(delegate *fiber.App) handler.AdapterFunc {
@@ -0,0 +1,61 @@
+//go:build !lambdahttpadapter.partial || (lambdahttpadapter.partial && lambdahttpadapter.gin)
+
+package adapter
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/its-felix/aws-lambda-go-http-adapter/handler"
+	"net/http"
+)
+
+// ginResponseWriter adds the interfaces gin expects from the http.ResponseWriter, for example to use gin.Context.Stream
+type ginResponseWriter struct {
+	http.ResponseWriter
+	ctx context.Context
+}
+
+func (w ginResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w ginResponseWriter) CloseNotify() <-chan bool {
+	ch := make(chan bool, 1)
+	go func() {
+		<-w.ctx.Done()
+		ch <- true
+	}()
+
+	return ch
+}
+
+func (w ginResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+type ginAdapter struct {
+	engine *gin.Engine
+}
+
+func (a ginAdapter) adapterFunc(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+	// CloseNotify fires once the invocation context is done, cancel ends its goroutines once the request was handled
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	a.engine.ServeHTTP(ginResponseWriter{w, ctx}, r)
+	return nil
+}
+
+func NewGinAdapter(delegate *gin.Engine) handler.AdapterFunc {
+	return ginAdapter{delegate}.adapterFunc
+}
+
+func GetContextGin(ctx *gin.Context) context.Context {
+	return ctx.Request.Context()
+}
+
+func GetSourceEventGin(ctx *gin.Context) any {
+	return handler.GetSourceEvent(GetContextGin(ctx))
+}
@@ -0,0 +1,21 @@
+//go:build !lambdahttpadapter.partial || (lambdahttpadapter.partial && lambdahttpadapter.gorilla)
+
+package adapter
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/its-felix/aws-lambda-go-http-adapter/handler"
+	"net/http"
+)
+
+// WithGorillaMuxVars makes the path parameters matched by API Gateway available using mux.Vars.
+// Note that a mux.Router replaces these with the variables of its own route match.
+func WithGorillaMuxVars(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if route, ok := handler.GetRoute(r.Context()); ok {
+			r = mux.SetURLVars(r, route.PathParameters)
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
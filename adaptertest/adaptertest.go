@@ -0,0 +1,337 @@
+// Package adaptertest provides a conformance test suite for handler.AdapterFunc implementations.
+package adaptertest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/its-felix/aws-lambda-go-http-adapter/handler"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Factory creates the AdapterFunc under test, which must pass all requests to the given http.Handler
+type Factory func(h http.Handler) handler.AdapterFunc
+
+type contextKey struct{}
+
+var suite = []struct {
+	name string
+	run  func(t *testing.T, factory Factory)
+}{
+	{"Request", testRequest},
+	{"Body", testBody},
+	{"LargeBody", testLargeBody},
+	{"MultiValueHeaders", testMultiValueHeaders},
+	{"Cookies", testCookies},
+	{"StatusCodes", testStatusCodes},
+	{"DefaultStatusCode", testDefaultStatusCode},
+	{"Context", testContext},
+	{"RemoteAddr", testRemoteAddr},
+	{"Panic", testPanic},
+	{"Streaming", testStreaming},
+}
+
+// Run runs the conformance test suite against the AdapterFunc created by factory, every case is run as a subtest
+func Run(t *testing.T, factory Factory) {
+	for _, tc := range suite {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			tc.run(t, factory)
+		})
+	}
+}
+
+func newRequest(method, target string, body io.Reader) *http.Request {
+	r := httptest.NewRequest(method, target, body)
+	r.URL.Scheme = "https"
+	r.Host = "example.com"
+	r.RemoteAddr = "203.0.113.1:4321"
+
+	return r
+}
+
+func serve(t *testing.T, factory Factory, h http.HandlerFunc, r *http.Request) *httptest.ResponseRecorder {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	if err := factory(h)(r.Context(), r, w); err != nil {
+		t.Fatalf("adapter returned an error: %v", err)
+	}
+
+	return w
+}
+
+func testRequest(t *testing.T, factory Factory) {
+	var method, requestURI, path, query, host string
+
+	r := newRequest(http.MethodPut, "/some/path?key=value&key=other", nil)
+	serve(t, factory, func(w http.ResponseWriter, r *http.Request) {
+		method, requestURI, path, query, host = r.Method, r.RequestURI, r.URL.Path, r.URL.RawQuery, r.Host
+	}, r)
+
+	if method != http.MethodPut {
+		t.Errorf("expected method %q, got %q", http.MethodPut, method)
+	}
+
+	if requestURI != "/some/path?key=value&key=other" {
+		t.Errorf("unexpected RequestURI: %q", requestURI)
+	}
+
+	if path != "/some/path" || query != "key=value&key=other" {
+		t.Errorf("unexpected URL: path=%q query=%q", path, query)
+	}
+
+	if host != "example.com" {
+		t.Errorf("unexpected host: %q", host)
+	}
+}
+
+func echoBody(w http.ResponseWriter, r *http.Request) {
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	_, _ = w.Write(b)
+}
+
+func testBody(t *testing.T, factory Factory) {
+	body := []byte("hello world\x00\xff")
+
+	r := newRequest(http.MethodPost, "/", bytes.NewReader(body))
+	w := serve(t, factory, echoBody, r)
+
+	if !bytes.Equal(w.Body.Bytes(), body) {
+		t.Errorf("expected body %q, got %q", body, w.Body.Bytes())
+	}
+}
+
+func testLargeBody(t *testing.T, factory Factory) {
+	body := bytes.Repeat([]byte("0123456789abcdef"), 64*1024)
+
+	r := newRequest(http.MethodPost, "/", bytes.NewReader(body))
+	w := serve(t, factory, echoBody, r)
+
+	if !bytes.Equal(w.Body.Bytes(), body) {
+		t.Errorf("expected body of %d bytes, got %d bytes", len(body), w.Body.Len())
+	}
+}
+
+func testMultiValueHeaders(t *testing.T, factory Factory) {
+	var values []string
+
+	r := newRequest(http.MethodGet, "/", nil)
+	r.Header.Add("X-Multi", "a")
+	r.Header.Add("X-Multi", "b")
+
+	w := serve(t, factory, func(w http.ResponseWriter, r *http.Request) {
+		values = r.Header.Values("X-Multi")
+
+		w.Header().Add("X-Multi", "c")
+		w.Header().Add("X-Multi", "d")
+		w.Header().Set("X-Single", "Wed, 21 Oct 2015 07:28:00 GMT")
+	}, r)
+
+	if !reflect.DeepEqual(values, []string{"a", "b"}) {
+		t.Errorf("expected request header values [a b], got %v", values)
+	}
+
+	if actual := w.Result().Header.Values("X-Multi"); !reflect.DeepEqual(actual, []string{"c", "d"}) {
+		t.Errorf("expected response header values [c d], got %v", actual)
+	}
+
+	if actual := w.Result().Header.Values("X-Single"); !reflect.DeepEqual(actual, []string{"Wed, 21 Oct 2015 07:28:00 GMT"}) {
+		t.Errorf("expected response header values containing a comma to be kept as is, got %v", actual)
+	}
+}
+
+func testCookies(t *testing.T, factory Factory) {
+	var cookies []*http.Cookie
+
+	r := newRequest(http.MethodGet, "/", nil)
+	r.AddCookie(&http.Cookie{Name: "a", Value: "1"})
+	r.AddCookie(&http.Cookie{Name: "b", Value: "2"})
+
+	expires := time.Date(2015, time.October, 21, 7, 28, 0, 0, time.UTC)
+
+	w := serve(t, factory, func(w http.ResponseWriter, r *http.Request) {
+		cookies = r.Cookies()
+
+		http.SetCookie(w, &http.Cookie{Name: "c", Value: "3", Path: "/", Expires: expires})
+		http.SetCookie(w, &http.Cookie{Name: "d", Value: "4", HttpOnly: true})
+	}, r)
+
+	if len(cookies) != 2 || cookies[0].String() != "a=1" || cookies[1].String() != "b=2" {
+		t.Errorf("expected request cookies [a=1 b=2], got %v", cookies)
+	}
+
+	result := w.Result().Cookies()
+	if len(result) != 2 {
+		t.Fatalf("expected 2 response cookies, got %v", w.Result().Header.Values("Set-Cookie"))
+	}
+
+	if result[0].Name != "c" || result[0].Value != "3" || !result[0].Expires.Equal(expires) {
+		t.Errorf("unexpected response cookie: %v", result[0])
+	}
+
+	if result[1].Name != "d" || result[1].Value != "4" || !result[1].HttpOnly {
+		t.Errorf("unexpected response cookie: %v", result[1])
+	}
+}
+
+func testStatusCodes(t *testing.T, factory Factory) {
+	for _, statusCode := range []int{http.StatusCreated, http.StatusNoContent, http.StatusFound, http.StatusNotFound, http.StatusServiceUnavailable} {
+		r := newRequest(http.MethodGet, "/", nil)
+		w := serve(t, factory, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(statusCode)
+		}, r)
+
+		if w.Code != statusCode {
+			t.Errorf("expected status %d, got %d", statusCode, w.Code)
+		}
+	}
+}
+
+func testDefaultStatusCode(t *testing.T, factory Factory) {
+	r := newRequest(http.MethodGet, "/", nil)
+	w := serve(t, factory, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("hello world"))
+	}, r)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+}
+
+func testContext(t *testing.T, factory Factory) {
+	var value any
+
+	ctx := context.WithValue(context.Background(), contextKey{}, "value")
+	r := newRequest(http.MethodGet, "/", nil).WithContext(ctx)
+
+	serve(t, factory, func(w http.ResponseWriter, r *http.Request) {
+		value = r.Context().Value(contextKey{})
+	}, r)
+
+	if value != "value" {
+		t.Errorf("expected the context passed to the adapter to be available to the handler, got %v", value)
+	}
+}
+
+func testRemoteAddr(t *testing.T, factory Factory) {
+	var remoteAddr string
+
+	r := newRequest(http.MethodGet, "/", nil)
+	serve(t, factory, func(w http.ResponseWriter, r *http.Request) {
+		remoteAddr = r.RemoteAddr
+	}, r)
+
+	if remoteAddr != "203.0.113.1:4321" {
+		t.Errorf("expected remote address %q, got %q", "203.0.113.1:4321", remoteAddr)
+	}
+}
+
+func testPanic(t *testing.T, factory Factory) {
+	defer func() {
+		if v := recover(); v != "panic from adaptertest" {
+			t.Errorf("expected the panic to be propagated to the caller, got %v", v)
+		}
+	}()
+
+	r := newRequest(http.MethodGet, "/", nil)
+	_ = factory(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("panic from adaptertest")
+	}))(r.Context(), r, httptest.NewRecorder())
+}
+
+// streamingRecorder records every chunk flushed by the adapter
+type streamingRecorder struct {
+	header  http.Header
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	flushed chan string
+}
+
+func (w *streamingRecorder) Header() http.Header {
+	return w.header
+}
+
+func (w *streamingRecorder) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.buf.Write(p)
+}
+
+func (w *streamingRecorder) WriteHeader(statusCode int) {
+}
+
+func (w *streamingRecorder) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.buf.Len() > 0 {
+		w.flushed <- w.buf.String()
+		w.buf.Reset()
+	}
+}
+
+func testStreaming(t *testing.T, factory Factory) {
+	w := &streamingRecorder{
+		header:  make(http.Header),
+		flushed: make(chan string, 10),
+	}
+
+	next := make(chan struct{})
+	done := make(chan error, 1)
+
+	r := newRequest(http.MethodGet, "/", nil)
+	go func() {
+		defer close(done)
+
+		done <- factory(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("first"))
+			if flusher, ok := w.(http.Flusher); ok {
+				flusher.Flush()
+			}
+
+			<-next
+			_, _ = w.Write([]byte("second"))
+		}))(r.Context(), r, w)
+	}()
+
+	select {
+	case chunk := <-w.flushed:
+		if chunk != "first" {
+			t.Errorf("expected the first chunk to be %q, got %q", "first", chunk)
+		}
+	case <-time.After(time.Second):
+		t.Error("expected the first chunk to be flushed before the handler returned")
+	}
+
+	close(next)
+
+	if err := <-done; err != nil {
+		t.Fatalf("adapter returned an error: %v", err)
+	}
+
+	w.Flush()
+
+	var rest []string
+	for len(w.flushed) > 0 {
+		rest = append(rest, <-w.flushed)
+	}
+
+	if !strings.HasSuffix(strings.Join(rest, ""), "second") {
+		t.Errorf("expected the remaining body to end with %q, got %q", "second", fmt.Sprint(rest))
+	}
+}
@@ -0,0 +1,175 @@
+// Package auth provides uniform access to the authorizer context of the source event, regardless of the event type.
+package auth
+
+import (
+	"context"
+	"fmt"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/its-felix/aws-lambda-go-http-adapter/handler"
+)
+
+// IAM is the IAM principal which signed the request
+type IAM struct {
+	AccessKey      string
+	AccountID      string
+	CallerID       string
+	PrincipalOrgID string
+	UserARN        string
+	UserID         string
+}
+
+// Cognito is the Cognito identity of the IAM principal which signed the request
+type Cognito struct {
+	IdentityID             string
+	IdentityPoolID         string
+	AuthenticationType     string
+	AuthenticationProvider string
+	// AMR contains the authentication methods references, only available for API Gateway V2
+	AMR []string
+}
+
+// JWTClaims returns the claims of the JWT validated by API Gateway (JWT authorizer for V2, Cognito user pool authorizer for V1 and WebSocket)
+func JWTClaims(ctx context.Context) (map[string]string, bool) {
+	switch event := handler.GetSourceEvent(ctx).(type) {
+	case events.APIGatewayProxyRequest:
+		return claimsV1(event.RequestContext.Authorizer)
+	case events.APIGatewayWebsocketProxyRequest:
+		authorizer, _ := event.RequestContext.Authorizer.(map[string]any)
+		return claimsV1(authorizer)
+	case events.APIGatewayV2HTTPRequest:
+		if a := event.RequestContext.Authorizer; a != nil && a.JWT != nil {
+			return a.JWT.Claims, true
+		}
+	}
+
+	return nil, false
+}
+
+// claimsV1 returns the claims set by the Cognito user pool authorizer, all values are converted to strings like for API Gateway V2
+func claimsV1(authorizer map[string]any) (map[string]string, bool) {
+	raw, ok := authorizer["claims"].(map[string]any)
+	if !ok {
+		return nil, false
+	}
+
+	claims := make(map[string]string, len(raw))
+	for k, v := range raw {
+		if s, ok := v.(string); ok {
+			claims[k] = s
+		} else {
+			claims[k] = fmt.Sprint(v)
+		}
+	}
+
+	return claims, true
+}
+
+// IAMIdentity returns the IAM principal if the request was authorized using IAM (API Gateway V1, V2, WebSocket, Lambda Function URL and VPC Lattice V2)
+func IAMIdentity(ctx context.Context) (IAM, bool) {
+	switch event := handler.GetSourceEvent(ctx).(type) {
+	case events.APIGatewayProxyRequest:
+		return iamV1(event.RequestContext.Identity)
+	case events.APIGatewayWebsocketProxyRequest:
+		return iamV1(event.RequestContext.Identity)
+	case events.APIGatewayV2HTTPRequest:
+		if a := event.RequestContext.Authorizer; a != nil && a.IAM != nil {
+			return IAM{
+				AccessKey:      a.IAM.AccessKey,
+				AccountID:      a.IAM.AccountID,
+				CallerID:       a.IAM.CallerID,
+				PrincipalOrgID: a.IAM.PrincipalOrgID,
+				UserARN:        a.IAM.UserARN,
+				UserID:         a.IAM.UserID,
+			}, true
+		}
+	case events.LambdaFunctionURLRequest:
+		if a := event.RequestContext.Authorizer; a != nil && a.IAM != nil {
+			return IAM{
+				AccessKey: a.IAM.AccessKey,
+				AccountID: a.IAM.AccountID,
+				CallerID:  a.IAM.CallerID,
+				UserARN:   a.IAM.UserARN,
+				UserID:    a.IAM.UserID,
+			}, true
+		}
+	}
+
+	return vpcLatticeIAM(ctx)
+}
+
+func iamV1(identity events.APIGatewayRequestIdentity) (IAM, bool) {
+	if identity.UserArn == "" {
+		return IAM{}, false
+	}
+
+	return IAM{
+		AccessKey: identity.AccessKey,
+		AccountID: identity.AccountID,
+		CallerID:  identity.Caller,
+		UserARN:   identity.UserArn,
+		UserID:    identity.User,
+	}, true
+}
+
+// LambdaAuthorizerContext returns the context returned by the Lambda authorizer (API Gateway V1, V2 and WebSocket)
+func LambdaAuthorizerContext(ctx context.Context) (map[string]any, bool) {
+	switch event := handler.GetSourceEvent(ctx).(type) {
+	case events.APIGatewayProxyRequest:
+		return lambdaAuthorizerContextV1(event.RequestContext.Authorizer)
+	case events.APIGatewayWebsocketProxyRequest:
+		authorizer, _ := event.RequestContext.Authorizer.(map[string]any)
+		return lambdaAuthorizerContextV1(authorizer)
+	case events.APIGatewayV2HTTPRequest:
+		if a := event.RequestContext.Authorizer; a != nil && a.Lambda != nil {
+			return a.Lambda, true
+		}
+	}
+
+	return nil, false
+}
+
+// lambdaAuthorizerContextV1 returns the authorizer map unless it was set by a Cognito user pool authorizer
+func lambdaAuthorizerContextV1(authorizer map[string]any) (map[string]any, bool) {
+	if len(authorizer) == 0 {
+		return nil, false
+	}
+
+	if _, ok := authorizer["claims"]; ok {
+		return nil, false
+	}
+
+	return authorizer, true
+}
+
+// CognitoIdentity returns the Cognito identity if the request was signed using credentials of a Cognito identity pool (API Gateway V1, V2 and WebSocket)
+func CognitoIdentity(ctx context.Context) (Cognito, bool) {
+	switch event := handler.GetSourceEvent(ctx).(type) {
+	case events.APIGatewayProxyRequest:
+		return cognitoV1(event.RequestContext.Identity)
+	case events.APIGatewayWebsocketProxyRequest:
+		return cognitoV1(event.RequestContext.Identity)
+	case events.APIGatewayV2HTTPRequest:
+		if a := event.RequestContext.Authorizer; a != nil && a.IAM != nil && a.IAM.CognitoIdentity.IdentityID != "" {
+			return Cognito{
+				IdentityID:     a.IAM.CognitoIdentity.IdentityID,
+				IdentityPoolID: a.IAM.CognitoIdentity.IdentityPoolID,
+				AMR:            a.IAM.CognitoIdentity.AMR,
+			}, true
+		}
+	}
+
+	return Cognito{}, false
+}
+
+func cognitoV1(identity events.APIGatewayRequestIdentity) (Cognito, bool) {
+	if identity.CognitoIdentityID == "" {
+		return Cognito{}, false
+	}
+
+	return Cognito{
+		IdentityID:             identity.CognitoIdentityID,
+		IdentityPoolID:         identity.CognitoIdentityPoolID,
+		AuthenticationType:     identity.CognitoAuthenticationType,
+		AuthenticationProvider: identity.CognitoAuthenticationProvider,
+	}, true
+}
@@ -0,0 +1,132 @@
+//go:build !lambdahttpadapter.partial || (lambdahttpadapter.partial && lambdahttpadapter.apigwv1 && lambdahttpadapter.apigwv2 && lambdahttpadapter.functionurl)
+
+package auth_test
+
+import (
+	"context"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/its-felix/aws-lambda-go-http-adapter/auth"
+	"github.com/its-felix/aws-lambda-go-http-adapter/handler"
+	"github.com/its-felix/aws-lambda-go-http-adapter/handlertest"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+// capture runs the handler and returns the context of the request
+func capture[In, Out any](t *testing.T, newHandler func(handler.AdapterFunc, ...handler.Option) func(context.Context, In) (Out, error), event In) context.Context {
+	t.Helper()
+
+	var ctx context.Context
+	h := newHandler(func(_ context.Context, r *http.Request, w http.ResponseWriter) error {
+		ctx = r.Context()
+		return nil
+	})
+
+	if _, err := h(context.Background(), event); err != nil {
+		t.Fatal(err)
+	}
+
+	return ctx
+}
+
+func TestJWTClaims(t *testing.T) {
+	v1 := capture(t, handler.NewAPIGatewayV1Handler, handlertest.APIGatewayV1().GET("/").Modify(func(event *events.APIGatewayProxyRequest) {
+		event.RequestContext.Authorizer = map[string]any{
+			"claims": map[string]any{"sub": "user", "email_verified": true},
+		}
+	}).Build())
+
+	v2 := capture(t, handler.NewAPIGatewayV2Handler, handlertest.APIGatewayV2().GET("/").Modify(func(event *events.APIGatewayV2HTTPRequest) {
+		event.RequestContext.Authorizer = &events.APIGatewayV2HTTPRequestContextAuthorizerDescription{
+			JWT: &events.APIGatewayV2HTTPRequestContextAuthorizerJWTDescription{
+				Claims: map[string]string{"sub": "user", "email_verified": "true"},
+			},
+		}
+	}).Build())
+
+	for name, ctx := range map[string]context.Context{"v1": v1, "v2": v2} {
+		claims, ok := auth.JWTClaims(ctx)
+		if !ok || !reflect.DeepEqual(claims, map[string]string{"sub": "user", "email_verified": "true"}) {
+			t.Errorf("%s: unexpected claims: %v", name, claims)
+		}
+
+		if _, ok := auth.LambdaAuthorizerContext(ctx); ok {
+			t.Errorf("%s: expected no lambda authorizer context", name)
+		}
+	}
+
+	if _, ok := auth.JWTClaims(capture(t, handler.NewFunctionURLHandler, handlertest.FunctionURL().GET("/").Build())); ok {
+		t.Error("expected no claims for a request without authorizer")
+	}
+}
+
+func TestIAMIdentity(t *testing.T) {
+	v1 := capture(t, handler.NewAPIGatewayV1Handler, handlertest.APIGatewayV1().GET("/").Modify(func(event *events.APIGatewayProxyRequest) {
+		event.RequestContext.Identity.AccountID = "123456789012"
+		event.RequestContext.Identity.UserArn = "arn:aws:iam::123456789012:user/example"
+		event.RequestContext.Identity.CognitoIdentityID = "eu-central-1:identity"
+		event.RequestContext.Identity.CognitoIdentityPoolID = "eu-central-1:pool"
+	}).Build())
+
+	v2 := capture(t, handler.NewAPIGatewayV2Handler, handlertest.APIGatewayV2().GET("/").Modify(func(event *events.APIGatewayV2HTTPRequest) {
+		event.RequestContext.Authorizer = &events.APIGatewayV2HTTPRequestContextAuthorizerDescription{
+			IAM: &events.APIGatewayV2HTTPRequestContextAuthorizerIAMDescription{
+				AccountID: "123456789012",
+				UserARN:   "arn:aws:iam::123456789012:user/example",
+				CognitoIdentity: events.APIGatewayV2HTTPRequestContextAuthorizerCognitoIdentity{
+					IdentityID:     "eu-central-1:identity",
+					IdentityPoolID: "eu-central-1:pool",
+				},
+			},
+		}
+	}).Build())
+
+	functionURL := capture(t, handler.NewFunctionURLHandler, handlertest.FunctionURL().GET("/").Modify(func(event *events.LambdaFunctionURLRequest) {
+		event.RequestContext.Authorizer = &events.LambdaFunctionURLRequestContextAuthorizerDescription{
+			IAM: &events.LambdaFunctionURLRequestContextAuthorizerIAMDescription{
+				AccountID: "123456789012",
+				UserARN:   "arn:aws:iam::123456789012:user/example",
+			},
+		}
+	}).Build())
+
+	for name, ctx := range map[string]context.Context{"v1": v1, "v2": v2, "functionurl": functionURL} {
+		iam, ok := auth.IAMIdentity(ctx)
+		if !ok || iam.AccountID != "123456789012" || iam.UserARN != "arn:aws:iam::123456789012:user/example" {
+			t.Errorf("%s: unexpected identity: %+v", name, iam)
+		}
+
+		cognito, ok := auth.CognitoIdentity(ctx)
+		if name == "functionurl" {
+			if ok {
+				t.Errorf("%s: expected no cognito identity", name)
+			}
+		} else if !ok || cognito.IdentityID != "eu-central-1:identity" || cognito.IdentityPoolID != "eu-central-1:pool" {
+			t.Errorf("%s: unexpected cognito identity: %+v", name, cognito)
+		}
+	}
+}
+
+func TestLambdaAuthorizerContext(t *testing.T) {
+	v1 := capture(t, handler.NewAPIGatewayV1Handler, handlertest.APIGatewayV1().GET("/").Modify(func(event *events.APIGatewayProxyRequest) {
+		event.RequestContext.Authorizer = map[string]any{"principalId": "user", "tenant": "example"}
+	}).Build())
+
+	v2 := capture(t, handler.NewAPIGatewayV2Handler, handlertest.APIGatewayV2().GET("/").Modify(func(event *events.APIGatewayV2HTTPRequest) {
+		event.RequestContext.Authorizer = &events.APIGatewayV2HTTPRequestContextAuthorizerDescription{
+			Lambda: map[string]any{"tenant": "example"},
+		}
+	}).Build())
+
+	for name, ctx := range map[string]context.Context{"v1": v1, "v2": v2} {
+		lambdaCtx, ok := auth.LambdaAuthorizerContext(ctx)
+		if !ok || lambdaCtx["tenant"] != "example" {
+			t.Errorf("%s: unexpected lambda authorizer context: %v", name, lambdaCtx)
+		}
+
+		if _, ok := auth.JWTClaims(ctx); ok {
+			t.Errorf("%s: expected no claims", name)
+		}
+	}
+}
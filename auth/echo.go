@@ -0,0 +1,23 @@
+//go:build !lambdahttpadapter.partial || (lambdahttpadapter.partial && lambdahttpadapter.echo)
+
+package auth
+
+import (
+	"github.com/labstack/echo/v4"
+)
+
+func JWTClaimsEcho(c echo.Context) (map[string]string, bool) {
+	return JWTClaims(c.Request().Context())
+}
+
+func IAMIdentityEcho(c echo.Context) (IAM, bool) {
+	return IAMIdentity(c.Request().Context())
+}
+
+func LambdaAuthorizerContextEcho(c echo.Context) (map[string]any, bool) {
+	return LambdaAuthorizerContext(c.Request().Context())
+}
+
+func CognitoIdentityEcho(c echo.Context) (Cognito, bool) {
+	return CognitoIdentity(c.Request().Context())
+}
@@ -0,0 +1,24 @@
+//go:build !lambdahttpadapter.partial || (lambdahttpadapter.partial && lambdahttpadapter.fiber)
+
+package auth
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/its-felix/aws-lambda-go-http-adapter/adapter"
+)
+
+func JWTClaimsFiber(c *fiber.Ctx) (map[string]string, bool) {
+	return JWTClaims(adapter.GetContextFiber(c))
+}
+
+func IAMIdentityFiber(c *fiber.Ctx) (IAM, bool) {
+	return IAMIdentity(adapter.GetContextFiber(c))
+}
+
+func LambdaAuthorizerContextFiber(c *fiber.Ctx) (map[string]any, bool) {
+	return LambdaAuthorizerContext(adapter.GetContextFiber(c))
+}
+
+func CognitoIdentityFiber(c *fiber.Ctx) (Cognito, bool) {
+	return CognitoIdentity(adapter.GetContextFiber(c))
+}
@@ -0,0 +1,23 @@
+//go:build !lambdahttpadapter.partial || (lambdahttpadapter.partial && lambdahttpadapter.gin)
+
+package auth
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func JWTClaimsGin(c *gin.Context) (map[string]string, bool) {
+	return JWTClaims(c.Request.Context())
+}
+
+func IAMIdentityGin(c *gin.Context) (IAM, bool) {
+	return IAMIdentity(c.Request.Context())
+}
+
+func LambdaAuthorizerContextGin(c *gin.Context) (map[string]any, bool) {
+	return LambdaAuthorizerContext(c.Request.Context())
+}
+
+func CognitoIdentityGin(c *gin.Context) (Cognito, bool) {
+	return CognitoIdentity(c.Request.Context())
+}
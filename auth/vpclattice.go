@@ -0,0 +1,20 @@
+//go:build !lambdahttpadapter.partial || (lambdahttpadapter.partial && lambdahttpadapter.vpclattice)
+
+package auth
+
+import (
+	"context"
+	"github.com/its-felix/aws-lambda-go-http-adapter/handler"
+)
+
+func vpcLatticeIAM(ctx context.Context) (IAM, bool) {
+	identity, ok := handler.GetVPCLatticeIdentity(ctx)
+	if !ok || identity.Type != "AWS_IAM" {
+		return IAM{}, false
+	}
+
+	return IAM{
+		PrincipalOrgID: identity.PrincipalOrgID,
+		UserARN:        identity.Principal,
+	}, true
+}
@@ -0,0 +1,9 @@
+//go:build lambdahttpadapter.partial && !lambdahttpadapter.vpclattice
+
+package auth
+
+import "context"
+
+func vpcLatticeIAM(ctx context.Context) (IAM, bool) {
+	return IAM{}, false
+}
@@ -1,6 +1,7 @@
 package aws_lambda_go_http_adapter
 
 import (
+	"bufio"
 	"context"
 	"encoding/base64"
 	"encoding/json"
@@ -150,7 +151,7 @@ func newFiberAdapter() handler.AdapterFunc {
 		result := make(map[string]string)
 		result["Method"] = ctx.Method()
 		result["URL"] = ctx.Request().URI().String()
-		result["RemoteAddr"] = ctx.IP() + ":http" // fiber uses net.ResolveTCPAddr which resolves :http to :80
+		result["RemoteAddr"] = ctx.Context().RemoteAddr().String()
 		result["Body"] = string(ctx.Body())
 
 		return ctx.JSON(result)
@@ -286,7 +287,7 @@ func runTestFunctionURLPOST[T any](t *testing.T, h func(context.Context, events.
 	expectedBody := map[string]string{
 		"Method":     "POST",
 		"URL":        "https://0dhg9709da0dhg9709da0dhg9709da.lambda-url.eu-central-1.on.aws/example?key=value",
-		"RemoteAddr": "127.0.0.1:http",
+		"RemoteAddr": "127.0.0.1:0",
 		"Body":       "hello world",
 	}
 
@@ -389,3 +390,117 @@ func runTestFunctionURLDelayed[T any](t *testing.T, h func(context.Context, even
 		t.Error("request/response didnt match")
 	}
 }
+
+func TestFunctionURLStreamingFiberBodyStream(t *testing.T) {
+	proceed := make(chan struct{})
+
+	app := fiber.New()
+	app.All("*", func(ctx *fiber.Ctx) error {
+		ctx.Set("Content-Type", "text/event-stream")
+		ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+			_, _ = w.WriteString("data: first\n\n")
+			_ = w.Flush()
+
+			<-proceed
+
+			_, _ = w.WriteString("data: second\n\n")
+			_ = w.Flush()
+		})
+
+		return nil
+	})
+
+	h := handler.NewFunctionURLStreamingHandler(adapter.NewFiberAdapter(app))
+	res, err := h(context.Background(), newFunctionURLRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() {
+		if rc, ok := res.Body.(io.Closer); ok {
+			_ = rc.Close()
+		}
+	}()
+
+	if res.Headers["Content-Type"] != "text/event-stream" {
+		t.Error("expected Content-Type to be text/event-stream")
+	}
+
+	if _, ok := res.Headers["Transfer-Encoding"]; ok {
+		t.Error("expected Transfer-Encoding not to be passed through")
+	}
+
+	// the first event must be readable while the stream writer is still blocked
+	first := make([]byte, len("data: first\n\n"))
+	if _, err = io.ReadFull(res.Body, first); err != nil {
+		t.Fatal(err)
+	}
+
+	if string(first) != "data: first\n\n" {
+		t.Errorf("unexpected first event: %q", string(first))
+	}
+
+	close(proceed)
+
+	rest, _ := io.ReadAll(res.Body)
+	if string(rest) != "data: second\n\n" {
+		t.Errorf("unexpected second event: %q", string(rest))
+	}
+}
+
+func TestFunctionURLStreamingFlush(t *testing.T) {
+	newAdapters := map[string]func(proceed <-chan struct{}) handler.AdapterFunc{
+		"vanilla": func(proceed <-chan struct{}) handler.AdapterFunc {
+			return adapter.NewVanillaAdapter(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/plain")
+				_, _ = w.Write([]byte("first"))
+
+				if err := http.NewResponseController(w).Flush(); err != nil {
+					t.Error(err)
+				}
+
+				<-proceed
+				_, _ = w.Write([]byte("second"))
+			}))
+		},
+		"echo": func(proceed <-chan struct{}) handler.AdapterFunc {
+			app := echo.New()
+			app.Any("*", func(c echo.Context) error {
+				c.Response().Header().Set("Content-Type", "text/plain")
+				_, _ = c.Response().Write([]byte("first"))
+				c.Response().Flush()
+
+				<-proceed
+				_, err := c.Response().Write([]byte("second"))
+				return err
+			})
+
+			return adapter.NewEchoAdapter(app)
+		},
+	}
+
+	for name, newAdapter := range newAdapters {
+		t.Run(name, func(t *testing.T) {
+			proceed := make(chan struct{})
+			h := handler.NewFunctionURLStreamingHandler(newAdapter(proceed))
+
+			res, err := h(context.Background(), newFunctionURLRequest())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// the first chunk must be readable while the handler is still blocked
+			first := make([]byte, len("first"))
+			if _, err = io.ReadFull(res.Body, first); err != nil {
+				t.Fatal(err)
+			}
+
+			close(proceed)
+
+			rest, _ := io.ReadAll(res.Body)
+			if string(first)+string(rest) != "firstsecond" {
+				t.Errorf("unexpected body: %q", string(first)+string(rest))
+			}
+		})
+	}
+}
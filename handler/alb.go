@@ -0,0 +1,170 @@
+//go:build !lambdahttpadapter.partial || (lambdahttpadapter.partial && lambdahttpadapter.alb)
+
+package handler
+
+import (
+	"context"
+	"github.com/aws/aws-lambda-go/events"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	registerAutoHandler(eventFormatALB, handleALB, func(statusCode int, headers http.Header, body []byte, o *options) events.ALBTargetGroupResponse {
+		return newALBResponse(statusCode, headers, body, false, o)
+	})
+}
+
+func isALBMultiValue(event events.ALBTargetGroupRequest) bool {
+	return event.MultiValueHeaders != nil || event.MultiValueQueryStringParameters != nil
+}
+
+// buildALBQuery joins the query parameters without encoding them again:
+// ALB passes them exactly as they were sent by the client (already percent-encoded).
+// The keys are sorted to make the query deterministic, the values of a key keep their order.
+func buildALBQuery(event events.ALBTargetGroupRequest) string {
+	values := event.MultiValueQueryStringParameters
+	if !isALBMultiValue(event) {
+		values = make(map[string][]string, len(event.QueryStringParameters))
+		for k, v := range event.QueryStringParameters {
+			values[k] = []string{v}
+		}
+	}
+
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	parts := make([]string, 0)
+	for _, k := range keys {
+		for _, v := range values[k] {
+			parts = append(parts, k+"="+v)
+		}
+	}
+
+	return strings.Join(parts, "&")
+}
+
+func convertALBRequest(ctx context.Context, event events.ALBTargetGroupRequest) (*http.Request, error) {
+	headers := make(http.Header)
+
+	if isALBMultiValue(event) {
+		for k, values := range event.MultiValueHeaders {
+			for _, v := range values {
+				headers.Add(k, v)
+			}
+		}
+	} else {
+		for k, v := range event.Headers {
+			headers.Add(k, v)
+		}
+	}
+
+	rUrl := buildFullRequestURL(headers.Get("Host"), event.Path, "", buildALBQuery(event))
+	req, err := http.NewRequestWithContext(ctx, event.HTTPMethod, rUrl, getBody(event.Body, event.IsBase64Encoded))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header = headers
+
+	if strings.EqualFold(headers.Get("X-Forwarded-Proto"), "http") {
+		req.URL.Scheme = "http"
+	}
+
+	// ALB appends the address of the client it received the request from to X-Forwarded-For
+	var sourceIP string
+	if xff := headers.Values("X-Forwarded-For"); len(xff) > 0 {
+		parts := strings.Split(xff[len(xff)-1], ",")
+		sourceIP = strings.TrimSpace(parts[len(parts)-1])
+	}
+
+	// with mTLS, ALB passes the URL-encoded client certificate in verify (leaf) or passthrough mode
+	clientCertPEM := headers.Get("X-Amzn-Mtls-Clientcert-Leaf")
+	if clientCertPEM == "" {
+		clientCertPEM = headers.Get("X-Amzn-Mtls-Clientcert")
+	}
+
+	clientCertPEM, _ = url.PathUnescape(clientCertPEM)
+
+	setHost(req, "")
+	req.TLS = newTLSConnectionState(req, clientCertPEM)
+	req = withSourceIP(req, sourceIP)
+	req.RequestURI = req.URL.RequestURI()
+
+	return req, nil
+}
+
+func newALBResponse(statusCode int, headers http.Header, body []byte, multiValue bool, o *options) events.ALBTargetGroupResponse {
+	res := events.ALBTargetGroupResponse{
+		StatusCode:        statusCode,
+		StatusDescription: strconv.Itoa(statusCode) + " " + http.StatusText(statusCode),
+	}
+
+	if multiValue {
+		res.MultiValueHeaders = make(map[string][]string)
+	} else {
+		res.Headers = make(map[string]string)
+	}
+
+	for k, values := range headers {
+		if multiValue {
+			res.MultiValueHeaders[k] = values
+		} else if len(values) == 0 {
+			res.Headers[k] = ""
+		} else if len(values) == 1 {
+			res.Headers[k] = values[0]
+		} else if strings.EqualFold("set-cookie", k) {
+			// cookies can't be folded, only one of them can be sent in single value mode
+			res.Headers[k] = values[len(values)-1]
+		} else {
+			res.Headers[k] = o.foldHeader(k, values)
+		}
+	}
+
+	res.Body, res.IsBase64Encoded = o.encodeBody(headers, body)
+
+	return res
+}
+
+func handleALB(ctx context.Context, event events.ALBTargetGroupRequest, adapter AdapterFunc, o *options) (events.ALBTargetGroupResponse, error) {
+	newResponse := func(statusCode int, headers http.Header, body []byte, o *options) events.ALBTargetGroupResponse {
+		return newALBResponse(statusCode, headers, body, isALBMultiValue(event), o)
+	}
+
+	req, err := convertALBRequest(ctx, event)
+	if err != nil {
+		return respondError(ctx, o, newResponse, ErrRequestConversion, err)
+	}
+
+	o.resolveClientIP(req)
+
+	if err = o.decompressRequest(req); err != nil {
+		return respondError(ctx, o, newResponse, ErrRequestConversion, err)
+	}
+
+	ctx = req.Context()
+
+	w := newBufferedResponseWriter()
+
+	if err = o.runAdapter(ctx, adapter, req, w); err != nil {
+		return respondError(ctx, o, newResponse, ErrAdapter, err)
+	}
+
+	statusCode, headers, b, err := w.result(ctx, o)
+	if err != nil {
+		return respondError(ctx, o, newResponse, ErrResponseBody, err)
+	}
+
+	return finalizeResponse(ctx, o, newResponse, w.encoding, statusCode, headers, b)
+}
+
+func NewALBHandler(adapter AdapterFunc, opts ...Option) func(context.Context, events.ALBTargetGroupRequest) (events.ALBTargetGroupResponse, error) {
+	return NewHandler(withOptions(handleALB, newOptions(opts)), adapter)
+}
@@ -0,0 +1,124 @@
+//go:build !lambdahttpadapter.partial || (lambdahttpadapter.partial && lambdahttpadapter.alb)
+
+package handler
+
+import (
+	"context"
+	"github.com/aws/aws-lambda-go/events"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func newALBTestAdapter(t *testing.T, caught **http.Request) AdapterFunc {
+	return func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+		*caught = r
+
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Add("Set-Cookie", "a=1")
+		w.Header().Add("Set-Cookie", "b=2")
+		w.WriteHeader(http.StatusCreated)
+
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+
+		_, _ = w.Write(b)
+		return nil
+	}
+}
+
+func TestALBSingleValue(t *testing.T) {
+	var req *http.Request
+	h := NewALBHandler(newALBTestAdapter(t, &req))
+
+	res, err := h(context.Background(), events.ALBTargetGroupRequest{
+		HTTPMethod:            http.MethodPost,
+		Path:                  "/example",
+		QueryStringParameters: map[string]string{"key": "hello%20world"},
+		Headers: map[string]string{
+			"host":              "example.com",
+			"x-forwarded-for":   "10.0.0.1, 127.0.0.1",
+			"x-forwarded-proto": "http",
+		},
+		Body: "hello world",
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if req.URL.String() != "http://example.com/example?key=hello%20world" {
+		t.Errorf("unexpected url: %v", req.URL.String())
+	}
+
+	if req.URL.Query().Get("key") != "hello world" {
+		t.Error("expected query to be decoded exactly once")
+	}
+
+	if req.RemoteAddr != "127.0.0.1:0" {
+		t.Errorf("unexpected remote addr: %v", req.RemoteAddr)
+	}
+
+	if res.StatusCode != http.StatusCreated || res.StatusDescription != "201 Created" {
+		t.Errorf("unexpected status: %v %v", res.StatusCode, res.StatusDescription)
+	}
+
+	if res.MultiValueHeaders != nil {
+		t.Error("expected response to use single value headers")
+	}
+
+	if res.Headers["Content-Type"] != "text/plain" {
+		t.Error("expected Content-Type to be text/plain")
+	}
+
+	if res.Headers["Set-Cookie"] != "b=2" {
+		t.Error("expected the last cookie to be sent")
+	}
+
+	if res.Body != "hello world" || res.IsBase64Encoded {
+		t.Error("unexpected body")
+	}
+}
+
+func TestALBMultiValue(t *testing.T) {
+	var req *http.Request
+	h := NewALBHandler(newALBTestAdapter(t, &req))
+
+	res, err := h(context.Background(), events.ALBTargetGroupRequest{
+		HTTPMethod:                      http.MethodPost,
+		Path:                            "/example",
+		MultiValueQueryStringParameters: map[string][]string{"key": {"z", "a%2Bb"}},
+		MultiValueHeaders: map[string][]string{
+			"host":   {"example.com"},
+			"accept": {"text/plain", "application/json"},
+		},
+		Body: "hello world",
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(req.URL.Query()["key"], []string{"z", "a+b"}) {
+		t.Errorf("unexpected query: %v", req.URL.RawQuery)
+	}
+
+	if !reflect.DeepEqual(req.Header.Values("Accept"), []string{"text/plain", "application/json"}) {
+		t.Error("expected all header values to be present")
+	}
+
+	if res.Headers != nil {
+		t.Error("expected response to use multi value headers")
+	}
+
+	if !reflect.DeepEqual(res.MultiValueHeaders["Set-Cookie"], []string{"a=1", "b=2"}) {
+		t.Error("expected both cookies to be sent")
+	}
+
+	if !reflect.DeepEqual(res.MultiValueHeaders["Content-Length"], []string{"11"}) {
+		t.Error("expected Content-Length to be set")
+	}
+}
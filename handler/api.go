@@ -16,6 +16,7 @@ type RecoverFunc[In any, Out any] func(ctx context.Context, event In, panicValue
 func NewHandler[In any, Out any](handlerFunc HandlerFunc[In, Out], adapter AdapterFunc) func(context.Context, In) (Out, error) {
 	return func(ctx context.Context, event In) (Out, error) {
 		ctx = context.WithValue(ctx, sourceEventContextKey, event)
+		ctx = withColdStart(ctx)
 		return handlerFunc(ctx, event, adapter)
 	}
 }
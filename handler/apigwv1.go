@@ -3,18 +3,19 @@
 package handler
 
 import (
-	"bytes"
 	"context"
-	"encoding/base64"
 	"github.com/aws/aws-lambda-go/events"
-	"io"
 	"net/http"
 	"net/url"
-	"strconv"
-	"unicode/utf8"
 )
 
+func init() {
+	registerAutoHandler(eventFormatAPIGatewayV1, handleApiGwV1, newApiGwV1Response)
+}
+
 func convertApiGwV1Request(ctx context.Context, event events.APIGatewayProxyRequest) (*http.Request, error) {
+	ctx = withRoute(ctx, event.Resource, event.PathParameters)
+
 	q := make(url.Values)
 
 	if len(event.MultiValueQueryStringParameters) > 0 {
@@ -53,94 +54,74 @@ func convertApiGwV1Request(ctx context.Context, event events.APIGatewayProxyRequ
 		req.ProtoMajor, req.ProtoMinor = pMajor, pMinor
 	}
 
-	req.RemoteAddr = event.RequestContext.Identity.SourceIP + ":http"
+	var clientCertPEM string
+	if event.RequestContext.Identity.ClientCert != nil {
+		clientCertPEM = event.RequestContext.Identity.ClientCert.ClientCertPem
+	}
+
+	setHost(req, event.RequestContext.DomainName)
+	req.TLS = newTLSConnectionState(req, clientCertPEM)
+	req = withSourceIP(req, event.RequestContext.Identity.SourceIP)
 	req.RequestURI = req.URL.RequestURI()
+	setPathValues(req)
 
 	return req, nil
 }
 
-type apiGwV1ResponseWriter struct {
-	headersWritten   bool
-	contentTypeSet   bool
-	contentLengthSet bool
-	headers          http.Header
-	body             bytes.Buffer
-	res              events.APIGatewayProxyResponse
-}
-
-func (w *apiGwV1ResponseWriter) Header() http.Header {
-	return w.headers
-}
-
-func (w *apiGwV1ResponseWriter) Write(p []byte) (int, error) {
-	w.WriteHeader(http.StatusOK)
-	return w.body.Write(p)
-}
+func newApiGwV1Response(statusCode int, headers http.Header, body []byte, o *options) events.APIGatewayProxyResponse {
+	res := events.APIGatewayProxyResponse{
+		StatusCode: statusCode,
+		Headers:    make(map[string]string),
+	}
 
-func (w *apiGwV1ResponseWriter) WriteHeader(statusCode int) {
-	if !w.headersWritten {
-		w.headersWritten = true
-		w.res.StatusCode = statusCode
-
-		for k, values := range w.headers {
-			if len(values) == 0 {
-				w.res.Headers[k] = ""
-			} else if len(values) == 1 {
-				w.res.Headers[k] = values[0]
-			} else {
-				if w.res.MultiValueHeaders == nil {
-					w.res.MultiValueHeaders = make(map[string][]string)
-				}
-
-				w.res.MultiValueHeaders[k] = values
+	for k, values := range headers {
+		if len(values) == 0 {
+			res.Headers[k] = ""
+		} else if len(values) == 1 {
+			res.Headers[k] = values[0]
+		} else {
+			if res.MultiValueHeaders == nil {
+				res.MultiValueHeaders = make(map[string][]string)
 			}
+
+			res.MultiValueHeaders[k] = values
 		}
 	}
+
+	res.Body, res.IsBase64Encoded = o.encodeBody(headers, body)
+
+	return res
 }
 
-func handleApiGwV1(ctx context.Context, event events.APIGatewayProxyRequest, adapter AdapterFunc) (events.APIGatewayProxyResponse, error) {
+func handleApiGwV1(ctx context.Context, event events.APIGatewayProxyRequest, adapter AdapterFunc, o *options) (events.APIGatewayProxyResponse, error) {
 	req, err := convertApiGwV1Request(ctx, event)
 	if err != nil {
-		var def events.APIGatewayProxyResponse
-		return def, err
+		return respondError(ctx, o, newApiGwV1Response, ErrRequestConversion, err)
 	}
 
-	w := apiGwV1ResponseWriter{
-		headers: make(http.Header),
-		res: events.APIGatewayProxyResponse{
-			Headers: make(map[string]string),
-		},
-	}
+	o.resolveClientIP(req)
 
-	if err = adapter(ctx, req, &w); err != nil {
-		var def events.APIGatewayProxyResponse
-		return def, err
+	if err = o.decompressRequest(req); err != nil {
+		return respondError(ctx, o, newApiGwV1Response, ErrRequestConversion, err)
 	}
 
-	b, err := io.ReadAll(&w.body)
-	if err != nil {
-		var def events.APIGatewayProxyResponse
-		return def, err
-	}
+	req = o.stripPath(req, event.RequestContext.Stage)
+	ctx = req.Context()
 
-	if !w.contentTypeSet {
-		w.res.Headers["Content-Type"] = http.DetectContentType(b)
-	}
+	w := newBufferedResponseWriter()
 
-	if !w.contentLengthSet {
-		w.res.Headers["Content-Length"] = strconv.Itoa(len(b))
+	if err = o.runAdapter(ctx, adapter, req, w); err != nil {
+		return respondError(ctx, o, newApiGwV1Response, ErrAdapter, err)
 	}
 
-	if utf8.Valid(b) {
-		w.res.Body = string(b)
-	} else {
-		w.res.IsBase64Encoded = true
-		w.res.Body = base64.StdEncoding.EncodeToString(b)
+	statusCode, headers, b, err := w.result(ctx, o)
+	if err != nil {
+		return respondError(ctx, o, newApiGwV1Response, ErrResponseBody, err)
 	}
 
-	return w.res, nil
+	return finalizeResponse(ctx, o, newApiGwV1Response, w.encoding, statusCode, headers, b)
 }
 
-func NewAPIGatewayV1Handler(adapter AdapterFunc) func(context.Context, events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
-	return NewHandler(handleApiGwV1, adapter)
+func NewAPIGatewayV1Handler(adapter AdapterFunc, opts ...Option) func(context.Context, events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return NewHandler(withOptions(handleApiGwV1, newOptions(opts)), adapter)
 }
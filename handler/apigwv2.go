@@ -3,18 +3,19 @@
 package handler
 
 import (
-	"bytes"
 	"context"
-	"encoding/base64"
 	"github.com/aws/aws-lambda-go/events"
-	"io"
 	"net/http"
-	"strconv"
 	"strings"
-	"unicode/utf8"
 )
 
+func init() {
+	registerAutoHandler(eventFormatAPIGatewayV2, handleApiGwV2, newApiGwV2Response)
+}
+
 func convertApiGwV2Request(ctx context.Context, event events.APIGatewayV2HTTPRequest) (*http.Request, error) {
+	ctx = withRoute(ctx, event.RouteKey, event.PathParameters)
+
 	url := buildFullRequestURL(event.RequestContext.DomainName, event.RawPath, event.RequestContext.HTTP.Path, buildQuery(event.RawQueryString, event.QueryStringParameters))
 	req, err := http.NewRequestWithContext(ctx, event.RequestContext.HTTP.Method, url, getBody(event.Body, event.IsBase64Encoded))
 	if err != nil {
@@ -37,99 +38,74 @@ func convertApiGwV2Request(ctx context.Context, event events.APIGatewayV2HTTPReq
 		req.ProtoMajor, req.ProtoMinor = pMajor, pMinor
 	}
 
-	req.RemoteAddr = event.RequestContext.HTTP.SourceIP + ":http"
+	setHost(req, event.RequestContext.DomainName)
+	req.TLS = newTLSConnectionState(req, event.RequestContext.Authentication.ClientCert.ClientCertPem)
+	req = withSourceIP(req, event.RequestContext.HTTP.SourceIP)
 	req.RequestURI = req.URL.RequestURI()
+	setPathValues(req)
 
 	return req, nil
 }
 
-type apiGwV2ResponseWriter struct {
-	headersWritten   bool
-	contentTypeSet   bool
-	contentLengthSet bool
-	headers          http.Header
-	body             bytes.Buffer
-	res              events.APIGatewayV2HTTPResponse
-}
-
-func (w *apiGwV2ResponseWriter) Header() http.Header {
-	return w.headers
-}
-
-func (w *apiGwV2ResponseWriter) Write(p []byte) (int, error) {
-	w.WriteHeader(http.StatusOK)
-	return w.body.Write(p)
-}
-
-func (w *apiGwV2ResponseWriter) WriteHeader(statusCode int) {
-	if !w.headersWritten {
-		w.headersWritten = true
-		w.res.StatusCode = statusCode
+func newApiGwV2Response(statusCode int, headers http.Header, body []byte, o *options) events.APIGatewayV2HTTPResponse {
+	res := events.APIGatewayV2HTTPResponse{
+		StatusCode: statusCode,
+		Headers:    make(map[string]string),
+		Cookies:    make([]string, 0),
+	}
 
-		for k, values := range w.headers {
-			if strings.EqualFold("set-cookie", k) {
-				w.res.Cookies = values
+	for k, values := range headers {
+		if strings.EqualFold("set-cookie", k) {
+			res.Cookies = values
+		} else {
+			if len(values) == 0 {
+				res.Headers[k] = ""
+			} else if len(values) == 1 {
+				res.Headers[k] = values[0]
 			} else {
-				if len(values) == 0 {
-					w.res.Headers[k] = ""
-				} else if len(values) == 1 {
-					w.res.Headers[k] = values[0]
-				} else {
-					if w.res.MultiValueHeaders == nil {
-						w.res.MultiValueHeaders = make(map[string][]string)
-					}
-
-					w.res.MultiValueHeaders[k] = values
+				if res.MultiValueHeaders == nil {
+					res.MultiValueHeaders = make(map[string][]string)
 				}
+
+				res.MultiValueHeaders[k] = values
 			}
 		}
 	}
+
+	res.Body, res.IsBase64Encoded = o.encodeBody(headers, body)
+
+	return res
 }
 
-func handleApiGwV2(ctx context.Context, event events.APIGatewayV2HTTPRequest, adapter AdapterFunc) (events.APIGatewayV2HTTPResponse, error) {
+func handleApiGwV2(ctx context.Context, event events.APIGatewayV2HTTPRequest, adapter AdapterFunc, o *options) (events.APIGatewayV2HTTPResponse, error) {
 	req, err := convertApiGwV2Request(ctx, event)
 	if err != nil {
-		var def events.APIGatewayV2HTTPResponse
-		return def, err
+		return respondError(ctx, o, newApiGwV2Response, ErrRequestConversion, err)
 	}
 
-	w := apiGwV2ResponseWriter{
-		headers: make(http.Header),
-		res: events.APIGatewayV2HTTPResponse{
-			Headers: make(map[string]string),
-			Cookies: make([]string, 0),
-		},
-	}
+	o.resolveClientIP(req)
 
-	if err = adapter(ctx, req, &w); err != nil {
-		var def events.APIGatewayV2HTTPResponse
-		return def, err
+	if err = o.decompressRequest(req); err != nil {
+		return respondError(ctx, o, newApiGwV2Response, ErrRequestConversion, err)
 	}
 
-	b, err := io.ReadAll(&w.body)
-	if err != nil {
-		var def events.APIGatewayV2HTTPResponse
-		return def, err
-	}
+	req = o.stripPath(req, event.RequestContext.Stage)
+	ctx = req.Context()
 
-	if !w.contentTypeSet {
-		w.res.Headers["Content-Type"] = http.DetectContentType(b)
-	}
+	w := newBufferedResponseWriter()
 
-	if !w.contentLengthSet {
-		w.res.Headers["Content-Length"] = strconv.Itoa(len(b))
+	if err = o.runAdapter(ctx, adapter, req, w); err != nil {
+		return respondError(ctx, o, newApiGwV2Response, ErrAdapter, err)
 	}
 
-	if utf8.Valid(b) {
-		w.res.Body = string(b)
-	} else {
-		w.res.IsBase64Encoded = true
-		w.res.Body = base64.StdEncoding.EncodeToString(b)
+	statusCode, headers, b, err := w.result(ctx, o)
+	if err != nil {
+		return respondError(ctx, o, newApiGwV2Response, ErrResponseBody, err)
 	}
 
-	return w.res, nil
+	return finalizeResponse(ctx, o, newApiGwV2Response, w.encoding, statusCode, headers, b)
 }
 
-func NewAPIGatewayV2Handler(adapter AdapterFunc) func(context.Context, events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
-	return NewHandler(handleApiGwV2, adapter)
+func NewAPIGatewayV2Handler(adapter AdapterFunc, opts ...Option) func(context.Context, events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+	return NewHandler(withOptions(handleApiGwV2, newOptions(opts)), adapter)
 }
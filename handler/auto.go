@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+var ErrUnsupportedEventFormat = errors.New("unsupported event format")
+
+type eventFormat string
+
+const (
+	eventFormatAPIGatewayV1 eventFormat = "apigwv1"
+	eventFormatAPIGatewayV2 eventFormat = "apigwv2"
+	eventFormatFunctionURL  eventFormat = "functionurl"
+	eventFormatALB          eventFormat = "alb"
+	eventFormatVPCLatticeV1 eventFormat = "vpclatticev1"
+	eventFormatVPCLatticeV2 eventFormat = "vpclatticev2"
+	eventFormatCloudFront   eventFormat = "cloudfront"
+	eventFormatWebsocket    eventFormat = "websocket"
+)
+
+type autoHandlerFunc func(ctx context.Context, event json.RawMessage, adapter AdapterFunc, o *options) (json.RawMessage, error)
+
+// autoHandlers is populated by the event format implementations which are part of the build
+var autoHandlers = make(map[eventFormat]autoHandlerFunc)
+
+// registerAutoHandler registers the handler of an event format, newResponse is used to respond with an error if the event can't be unmarshalled
+func registerAutoHandler[In any, Out any](format eventFormat, handlerFunc optionsHandlerFunc[In, Out], newResponse func(statusCode int, headers http.Header, body []byte, o *options) Out) {
+	autoHandlers[format] = func(ctx context.Context, raw json.RawMessage, adapter AdapterFunc, o *options) (json.RawMessage, error) {
+		var event In
+		if err := json.Unmarshal(raw, &event); err != nil {
+			out, err := respondError(ctx, o, newResponse, ErrRequestConversion, err)
+			if err != nil {
+				return nil, err
+			}
+
+			return json.Marshal(out)
+		}
+
+		// replaces the raw event so that GetSourceEvent returns the typed event
+		ctx = context.WithValue(ctx, sourceEventContextKey, event)
+
+		out, err := handlerFunc(ctx, event, adapter, o)
+		if err != nil {
+			return nil, err
+		}
+
+		return json.Marshal(out)
+	}
+}
+
+// autoEventProbe contains the marker fields used to detect the format of an event
+type autoEventProbe struct {
+	Version    string `json:"version"`
+	HTTPMethod string `json:"httpMethod"`
+	RawPath    string `json:"raw_path"`
+	Records    []struct {
+		CF json.RawMessage `json:"cf"`
+	} `json:"Records"`
+	RequestContext struct {
+		ELB               json.RawMessage `json:"elb"`
+		ServiceNetworkARN string          `json:"serviceNetworkArn"`
+		ConnectionID      string          `json:"connectionId"`
+		DomainName        string          `json:"domainName"`
+	} `json:"requestContext"`
+}
+
+func detectEventFormat(raw json.RawMessage) (eventFormat, error) {
+	var probe autoEventProbe
+	if err := json.Unmarshal(raw, &probe); err != nil {
+		return "", err
+	}
+
+	switch {
+	case len(probe.Records) > 0 && len(probe.Records[0].CF) > 0:
+		return eventFormatCloudFront, nil
+
+	case len(probe.RequestContext.ELB) > 0:
+		return eventFormatALB, nil
+
+	case probe.RequestContext.ConnectionID != "":
+		return eventFormatWebsocket, nil
+
+	case probe.RequestContext.ServiceNetworkARN != "":
+		return eventFormatVPCLatticeV2, nil
+
+	case probe.RawPath != "":
+		return eventFormatVPCLatticeV1, nil
+
+	case probe.Version == "2.0":
+		// function url events share the payload format 2.0 with API Gateway V2 but are always served from a lambda-url domain
+		if strings.Contains(probe.RequestContext.DomainName, ".lambda-url.") {
+			return eventFormatFunctionURL, nil
+		}
+
+		return eventFormatAPIGatewayV2, nil
+
+	case probe.Version == "1.0", probe.HTTPMethod != "":
+		return eventFormatAPIGatewayV1, nil
+	}
+
+	return "", ErrUnsupportedEventFormat
+}
+
+func handleAuto(ctx context.Context, event json.RawMessage, adapter AdapterFunc, o *options) (json.RawMessage, error) {
+	// without a known format there is no response shape to map the error to
+	format, err := detectEventFormat(event)
+	if err != nil {
+		return nil, err
+	}
+
+	handlerFunc, ok := autoHandlers[format]
+	if !ok {
+		return nil, ErrUnsupportedEventFormat
+	}
+
+	return handlerFunc(ctx, event, adapter, o)
+}
+
+// NewAutoHandler creates a handler which accepts any of the event formats enabled in the build.
+// The format is detected from the raw event and the response is serialized in the matching shape.
+func NewAutoHandler(adapter AdapterFunc, opts ...Option) func(context.Context, json.RawMessage) (json.RawMessage, error) {
+	return NewHandler(withOptions(handleAuto, newOptions(opts)), adapter)
+}
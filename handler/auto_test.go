@@ -0,0 +1,145 @@
+//go:build !lambdahttpadapter.partial || (lambdahttpadapter.partial && lambdahttpadapter.alb && lambdahttpadapter.apigwv1 && lambdahttpadapter.apigwv2 && lambdahttpadapter.functionurl && lambdahttpadapter.vpclattice && lambdahttpadapter.websocket)
+
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/aws/aws-lambda-go/events"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestAutoHandler(t *testing.T) {
+	tests := map[string]struct {
+		event        string
+		expectedType reflect.Type
+		response     any
+	}{
+		"apigwv1": {
+			event:        `{"resource":"/","path":"/example","httpMethod":"GET","requestContext":{"domainName":"id.execute-api.eu-central-1.amazonaws.com"}}`,
+			expectedType: reflect.TypeOf(events.APIGatewayProxyRequest{}),
+			response:     &events.APIGatewayProxyResponse{},
+		},
+		"apigwv1 payload 1.0": {
+			event:        `{"version":"1.0","path":"/example","httpMethod":"GET","requestContext":{"domainName":"id.execute-api.eu-central-1.amazonaws.com"}}`,
+			expectedType: reflect.TypeOf(events.APIGatewayProxyRequest{}),
+			response:     &events.APIGatewayProxyResponse{},
+		},
+		"apigwv2": {
+			event:        `{"version":"2.0","rawPath":"/example","requestContext":{"domainName":"id.execute-api.eu-central-1.amazonaws.com","http":{"method":"GET"}}}`,
+			expectedType: reflect.TypeOf(events.APIGatewayV2HTTPRequest{}),
+			response:     &events.APIGatewayV2HTTPResponse{},
+		},
+		"functionurl": {
+			event:        `{"version":"2.0","rawPath":"/example","requestContext":{"domainName":"id.lambda-url.eu-central-1.on.aws","http":{"method":"GET"}}}`,
+			expectedType: reflect.TypeOf(events.LambdaFunctionURLRequest{}),
+			response:     &events.LambdaFunctionURLResponse{},
+		},
+		"alb": {
+			event:        `{"httpMethod":"GET","path":"/example","headers":{"host":"example.com"},"requestContext":{"elb":{"targetGroupArn":"arn"}}}`,
+			expectedType: reflect.TypeOf(events.ALBTargetGroupRequest{}),
+			response:     &events.ALBTargetGroupResponse{},
+		},
+		"websocket": {
+			event:        `{"requestContext":{"routeKey":"example","connectionId":"abc","domainName":"id.execute-api.eu-central-1.amazonaws.com"}}`,
+			expectedType: reflect.TypeOf(events.APIGatewayWebsocketProxyRequest{}),
+			response:     &events.APIGatewayProxyResponse{},
+		},
+		"vpclatticev1": {
+			event:        `{"raw_path":"/example","method":"GET","headers":{"host":"svc.example.com"}}`,
+			expectedType: reflect.TypeOf(VPCLatticeV1Request{}),
+			response:     &VPCLatticeResponse{},
+		},
+		"vpclatticev2": {
+			event:        `{"version":"2.0","path":"/example","method":"GET","headers":{"host":["svc.example.com"]},"requestContext":{"serviceNetworkArn":"arn"}}`,
+			expectedType: reflect.TypeOf(VPCLatticeV2Request{}),
+			response:     &VPCLatticeResponse{},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var caughtEvent any
+			var caughtPath string
+
+			h := NewAutoHandler(func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+				caughtEvent = GetSourceEvent(ctx)
+				caughtPath = r.URL.Path
+
+				w.WriteHeader(http.StatusTeapot)
+				_, _ = w.Write([]byte("hello world"))
+				return nil
+			})
+
+			raw, err := h(context.Background(), json.RawMessage(tt.event))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if reflect.TypeOf(caughtEvent) != tt.expectedType {
+				t.Errorf("expected source event of type %v, got %T", tt.expectedType, caughtEvent)
+			}
+
+			if caughtPath != "/example" {
+				t.Errorf("unexpected path: %v", caughtPath)
+			}
+
+			if err = json.Unmarshal(raw, tt.response); err != nil {
+				t.Fatal(err)
+			}
+
+			v := reflect.ValueOf(tt.response).Elem()
+			if v.FieldByName("StatusCode").Int() != http.StatusTeapot {
+				t.Error("expected status to be 418")
+			}
+
+			if v.FieldByName("Body").String() != "hello world" {
+				t.Error("expected body to be 'hello world'")
+			}
+		})
+	}
+}
+
+func TestAutoHandlerUnsupportedEvent(t *testing.T) {
+	h := NewAutoHandler(func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+		return nil
+	})
+
+	_, err := h(context.Background(), json.RawMessage(`{"Records":[]}`))
+	if !errors.Is(err, ErrUnsupportedEventFormat) {
+		t.Errorf("expected ErrUnsupportedEventFormat, got %v", err)
+	}
+}
+
+func TestAutoHandlerErrorResponder(t *testing.T) {
+	h := NewAutoHandler(func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+		t.Error("expected the adapter not to be called")
+		return nil
+	}, WithErrorResponder(ProblemDetailsErrorResponder))
+
+	t.Run("unsupported", func(t *testing.T) {
+		_, err := h(context.Background(), json.RawMessage(`{"Records":[]}`))
+		if !errors.Is(err, ErrUnsupportedEventFormat) {
+			t.Errorf("expected ErrUnsupportedEventFormat, got %v", err)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		out, err := h(context.Background(), json.RawMessage(`{"version":"2.0","rawPath":1}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var res events.APIGatewayV2HTTPResponse
+		if err = json.Unmarshal(out, &res); err != nil {
+			t.Fatal(err)
+		}
+
+		if res.StatusCode != http.StatusBadRequest || res.Headers["Content-Type"] != "application/problem+json" {
+			t.Errorf("unexpected response: %s", out)
+		}
+	})
+}
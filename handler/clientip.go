@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+)
+
+var sourceIPContextKey httpContextKey = "github.com/its-felix/aws-lambda-go-http-adapter/handler/clientip::sourceIPContextKey"
+
+// ForwardedHeader is the header WithForwardedClientIP takes the client IP from
+type ForwardedHeader int
+
+const (
+	// ForwardedHeaderXForwardedFor uses the X-Forwarded-For header, which is appended to by CloudFront, API Gateway and ALB
+	ForwardedHeaderXForwardedFor ForwardedHeader = iota + 1
+	// ForwardedHeaderForwarded uses the RFC 7239 Forwarded header, only proxies which append to it may be trusted
+	ForwardedHeaderForwarded
+	// ForwardedHeaderCloudFrontViewerAddress uses the CloudFront-Viewer-Address header, which is set by CloudFront if enabled in the origin request policy
+	ForwardedHeaderCloudFrontViewerAddress
+)
+
+// WithForwardedClientIP determines the client IP used for http.Request.RemoteAddr from the given header added by trusted proxies in front of the event source.
+// trustedHops is the number of these proxies, for example 1 if CloudFront is in front of API Gateway. Addresses added before them are ignored, since clients can send the header themselves.
+// trustedHops doesn't apply to ForwardedHeaderCloudFrontViewerAddress, which contains only the address of the viewer.
+func WithForwardedClientIP(header ForwardedHeader, trustedHops int) Option {
+	return func(o *options) {
+		o.forwardedHeader = header
+		o.trustedHops = trustedHops
+	}
+}
+
+// withSourceIP sets RemoteAddr to the source IP of the event and makes it available using GetSourceIP
+func withSourceIP(req *http.Request, sourceIP string) *http.Request {
+	req = req.WithContext(context.WithValue(req.Context(), sourceIPContextKey, sourceIP))
+	req.RemoteAddr = joinHostPort(sourceIP, "")
+
+	return req
+}
+
+// joinHostPort returns a valid ip:port address, the port is 0 if unknown
+func joinHostPort(ip, port string) string {
+	if port == "" {
+		port = "0"
+	}
+
+	return net.JoinHostPort(ip, port)
+}
+
+// splitHostPort parses addresses with an optional port, like 192.0.2.1, 192.0.2.1:1234, 2001:db8::1 or [2001:db8::1]:1234
+func splitHostPort(addr string) (net.IP, string) {
+	addr = strings.TrimSpace(addr)
+
+	if host, port, err := net.SplitHostPort(addr); err == nil {
+		return net.ParseIP(host), port
+	}
+
+	return net.ParseIP(strings.Trim(addr, "[]")), ""
+}
+
+// forwardedFor returns the addresses of the for parameters of the Forwarded header (RFC 7239)
+func forwardedFor(values []string) []string {
+	addrs := make([]string, 0)
+	for _, v := range values {
+		for _, element := range strings.Split(v, ",") {
+			for _, pair := range strings.Split(element, ";") {
+				k, v, _ := strings.Cut(strings.TrimSpace(pair), "=")
+				if strings.EqualFold(k, "for") {
+					addrs = append(addrs, strings.Trim(v, `"`))
+				}
+			}
+		}
+	}
+
+	return addrs
+}
+
+func xForwardedFor(values []string) []string {
+	addrs := make([]string, 0)
+	for _, v := range values {
+		for _, addr := range strings.Split(v, ",") {
+			addrs = append(addrs, strings.TrimSpace(addr))
+		}
+	}
+
+	return addrs
+}
+
+// resolveClientIP sets RemoteAddr to the client IP according to WithForwardedClientIP
+func (o *options) resolveClientIP(req *http.Request) {
+	var chain []string
+
+	switch o.forwardedHeader {
+	case ForwardedHeaderCloudFrontViewerAddress:
+		// CloudFront-Viewer-Address uses the ip:port format, also for IPv6 addresses without brackets
+		if v := req.Header.Get("CloudFront-Viewer-Address"); v != "" {
+			if i := strings.LastIndexByte(v, ':'); i != -1 {
+				if ip := net.ParseIP(v[:i]); ip != nil {
+					req.RemoteAddr = joinHostPort(ip.String(), v[i+1:])
+				}
+			}
+		}
+
+		return
+	case ForwardedHeaderForwarded:
+		chain = forwardedFor(req.Header.Values("Forwarded"))
+	case ForwardedHeaderXForwardedFor:
+		chain = xForwardedFor(req.Header.Values("X-Forwarded-For"))
+	}
+
+	if len(chain) == 0 {
+		return
+	}
+
+	// the source IP of the event is the last hop, which is already part of the chain for ALB and VPC Lattice
+	sourceIP, _ := GetSourceIP(req.Context())
+	if last, _ := splitHostPort(chain[len(chain)-1]); last == nil || last.String() != sourceIP {
+		chain = append(chain, sourceIP)
+	}
+
+	i := len(chain) - 1 - o.trustedHops
+	if i < 0 {
+		i = 0
+	}
+
+	if ip, port := splitHostPort(chain[i]); ip != nil {
+		req.RemoteAddr = joinHostPort(ip.String(), port)
+	}
+}
+
+// GetSourceIP returns the source IP of the event, which is the address of the last proxy if WithForwardedClientIP is used
+func GetSourceIP(ctx context.Context) (string, bool) {
+	ip, ok := ctx.Value(sourceIPContextKey).(string)
+	return ip, ok
+}
@@ -0,0 +1,89 @@
+//go:build !lambdahttpadapter.partial || (lambdahttpadapter.partial && lambdahttpadapter.alb && lambdahttpadapter.apigwv2)
+
+package handler
+
+import (
+	"context"
+	"github.com/aws/aws-lambda-go/events"
+	"net/http"
+	"testing"
+)
+
+func TestForwardedClientIP(t *testing.T) {
+	tests := map[string]struct {
+		opts       []Option
+		sourceIP   string
+		headers    map[string]string
+		remoteAddr string
+	}{
+		"default":                   {nil, "198.51.100.1", map[string]string{"X-Forwarded-For": "203.0.113.1"}, "198.51.100.1:0"},
+		"ipv6":                      {nil, "2001:db8::1", nil, "[2001:db8::1]:0"},
+		"x-forwarded-for":           {[]Option{WithForwardedClientIP(ForwardedHeaderXForwardedFor, 1)}, "198.51.100.1", map[string]string{"X-Forwarded-For": "192.0.2.1, 203.0.113.1"}, "203.0.113.1:0"},
+		"x-forwarded-for two hops":  {[]Option{WithForwardedClientIP(ForwardedHeaderXForwardedFor, 2)}, "198.51.100.1", map[string]string{"X-Forwarded-For": "192.0.2.1, 203.0.113.1"}, "192.0.2.1:0"},
+		"x-forwarded-for too short": {[]Option{WithForwardedClientIP(ForwardedHeaderXForwardedFor, 5)}, "198.51.100.1", map[string]string{"X-Forwarded-For": "203.0.113.1"}, "203.0.113.1:0"},
+		"x-forwarded-for invalid":   {[]Option{WithForwardedClientIP(ForwardedHeaderXForwardedFor, 1)}, "198.51.100.1", map[string]string{"X-Forwarded-For": "unknown"}, "198.51.100.1:0"},
+		"x-forwarded-for spoofed":   {[]Option{WithForwardedClientIP(ForwardedHeaderXForwardedFor, 1)}, "10.0.0.1", map[string]string{"Forwarded": "for=6.6.6.6", "X-Forwarded-For": "6.6.6.6, 198.51.100.7", "CloudFront-Viewer-Address": "6.6.6.6:1234"}, "198.51.100.7:0"},
+		"forwarded":                 {[]Option{WithForwardedClientIP(ForwardedHeaderForwarded, 1)}, "198.51.100.1", map[string]string{"Forwarded": `for=192.0.2.1;proto=https, for="[2001:db8::2]:4711"`, "X-Forwarded-For": "192.0.2.2"}, "[2001:db8::2]:4711"},
+		"forwarded absent":          {[]Option{WithForwardedClientIP(ForwardedHeaderForwarded, 1)}, "198.51.100.1", map[string]string{"X-Forwarded-For": "192.0.2.2"}, "198.51.100.1:0"},
+		"cloudfront viewer address": {[]Option{WithForwardedClientIP(ForwardedHeaderCloudFrontViewerAddress, 1)}, "198.51.100.1", map[string]string{"CloudFront-Viewer-Address": "2001:db8::3:4711", "X-Forwarded-For": "192.0.2.2"}, "[2001:db8::3]:4711"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var remoteAddr, sourceIP string
+
+			h := NewAPIGatewayV2Handler(func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+				remoteAddr = r.RemoteAddr
+				sourceIP, _ = GetSourceIP(ctx)
+				return nil
+			}, tt.opts...)
+
+			_, err := h(context.Background(), events.APIGatewayV2HTTPRequest{
+				RawPath: "/",
+				Headers: tt.headers,
+				RequestContext: events.APIGatewayV2HTTPRequestContext{
+					HTTP: events.APIGatewayV2HTTPRequestContextHTTPDescription{
+						Method:   http.MethodGet,
+						SourceIP: tt.sourceIP,
+					},
+				},
+			})
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if remoteAddr != tt.remoteAddr {
+				t.Errorf("expected remote addr %q, got %q", tt.remoteAddr, remoteAddr)
+			}
+
+			if sourceIP != tt.sourceIP {
+				t.Errorf("expected source ip %q, got %q", tt.sourceIP, sourceIP)
+			}
+		})
+	}
+}
+
+func TestForwardedClientIPALB(t *testing.T) {
+	var remoteAddr string
+
+	h := NewALBHandler(func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+		remoteAddr = r.RemoteAddr
+		return nil
+	}, WithForwardedClientIP(ForwardedHeaderXForwardedFor, 1))
+
+	// ALB appends the source IP to X-Forwarded-For
+	_, err := h(context.Background(), events.ALBTargetGroupRequest{
+		HTTPMethod: http.MethodGet,
+		Path:       "/",
+		Headers:    map[string]string{"x-forwarded-for": "192.0.2.1, 203.0.113.1, 198.51.100.1"},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if remoteAddr != "203.0.113.1:0" {
+		t.Errorf("expected remote addr %q, got %q", "203.0.113.1:0", remoteAddr)
+	}
+}
@@ -0,0 +1,392 @@
+//go:build !lambdahttpadapter.partial || (lambdahttpadapter.partial && lambdahttpadapter.cloudfront)
+
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var cloudFrontRequestContextKey httpContextKey = "github.com/its-felix/aws-lambda-go-http-adapter/handler/cloudfront::cloudFrontRequestContextKey"
+
+var (
+	ErrCloudFrontReadOnlyHeader     = errors.New("header is read-only for this Lambda@Edge event type")
+	ErrCloudFrontDisallowedHeader   = errors.New("header is not allowed in Lambda@Edge generated responses")
+	ErrCloudFrontSizeLimitExceeded  = errors.New("size limit for this Lambda@Edge event type exceeded")
+	ErrCloudFrontUnsupportedTrigger = errors.New("unsupported Lambda@Edge event type")
+)
+
+const (
+	CloudFrontEventTypeViewerRequest = "viewer-request"
+	CloudFrontEventTypeOriginRequest = "origin-request"
+)
+
+type cloudFrontLimits struct {
+	maxSize         int
+	readOnlyHeaders []string
+}
+
+var cloudFrontEventTypeLimits = map[string]cloudFrontLimits{
+	CloudFrontEventTypeViewerRequest: {
+		maxSize:         40 * 1024,
+		readOnlyHeaders: []string{"content-length", "host", "transfer-encoding", "via"},
+	},
+	CloudFrontEventTypeOriginRequest: {
+		maxSize: 1024 * 1024,
+		readOnlyHeaders: []string{
+			"accept-encoding",
+			"content-length",
+			"if-modified-since",
+			"if-none-match",
+			"if-range",
+			"if-unmodified-since",
+			"transfer-encoding",
+			"via",
+		},
+	},
+}
+
+var cloudFrontDisallowedResponseHeaders = []string{
+	"connection",
+	"expect",
+	"keep-alive",
+	"proxy-authenticate",
+	"proxy-authorization",
+	"proxy-connection",
+	"trailer",
+	"transfer-encoding",
+	"upgrade",
+	"via",
+	"x-accel-buffering",
+	"x-accel-charset",
+	"x-accel-limit-rate",
+	"x-accel-redirect",
+	"x-cache",
+	"x-forwarded-proto",
+	"x-real-ip",
+}
+
+var cloudFrontDisallowedResponseHeaderPrefixes = []string{"x-amz-cf-", "x-amzn-", "x-edge-"}
+
+func init() {
+	registerAutoHandler(eventFormatCloudFront, handleCloudFront, newCloudFrontResult)
+}
+
+// CloudFrontEvent is the event received by Lambda@Edge functions
+type CloudFrontEvent struct {
+	Records []CloudFrontEventRecord `json:"Records"`
+}
+
+func (e CloudFrontEvent) requestMetadata(m *RequestMetadata) {
+	if len(e.Records) > 0 {
+		m.SourceRequestID = e.Records[0].CF.Config.RequestID
+	}
+}
+
+func (e CloudFrontEvent) disallowsAmznHeaders() {}
+
+type CloudFrontEventRecord struct {
+	CF CloudFrontRecord `json:"cf"`
+}
+
+type CloudFrontRecord struct {
+	Config  CloudFrontConfig  `json:"config"`
+	Request CloudFrontRequest `json:"request"`
+}
+
+type CloudFrontConfig struct {
+	DistributionDomainName string `json:"distributionDomainName"`
+	DistributionID         string `json:"distributionId"`
+	EventType              string `json:"eventType"`
+	RequestID              string `json:"requestId"`
+}
+
+// CloudFrontHeaders maps lowercase header names to the list of values
+type CloudFrontHeaders map[string][]CloudFrontHeader
+
+type CloudFrontHeader struct {
+	Key   string `json:"key,omitempty"`
+	Value string `json:"value"`
+}
+
+type CloudFrontRequest struct {
+	ClientIP    string                 `json:"clientIp"`
+	Method      string                 `json:"method"`
+	URI         string                 `json:"uri"`
+	QueryString string                 `json:"querystring"`
+	Headers     CloudFrontHeaders      `json:"headers"`
+	Body        *CloudFrontRequestBody `json:"body,omitempty"`
+	Origin      json.RawMessage        `json:"origin,omitempty"`
+}
+
+type CloudFrontRequestBody struct {
+	Action         string `json:"action"`
+	Data           string `json:"data"`
+	Encoding       string `json:"encoding"`
+	InputTruncated bool   `json:"inputTruncated"`
+}
+
+type CloudFrontResponse struct {
+	Status            string            `json:"status"`
+	StatusDescription string            `json:"statusDescription,omitempty"`
+	Headers           CloudFrontHeaders `json:"headers,omitempty"`
+	Body              string            `json:"body,omitempty"`
+	BodyEncoding      string            `json:"bodyEncoding,omitempty"`
+}
+
+// CloudFrontResult is either a generated response or a request which is passed through to the origin
+type CloudFrontResult struct {
+	Request  *CloudFrontRequest
+	Response *CloudFrontResponse
+}
+
+func (r CloudFrontResult) MarshalJSON() ([]byte, error) {
+	if r.Response != nil {
+		return json.Marshal(r.Response)
+	}
+
+	return json.Marshal(r.Request)
+}
+
+func (r *CloudFrontResult) UnmarshalJSON(b []byte) error {
+	var probe struct {
+		Status string `json:"status"`
+	}
+
+	if err := json.Unmarshal(b, &probe); err != nil {
+		return err
+	}
+
+	if probe.Status != "" {
+		r.Response = new(CloudFrontResponse)
+		return json.Unmarshal(b, r.Response)
+	}
+
+	r.Request = new(CloudFrontRequest)
+	return json.Unmarshal(b, r.Request)
+}
+
+func (h CloudFrontHeaders) clone() CloudFrontHeaders {
+	c := make(CloudFrontHeaders, len(h))
+	for k, values := range h {
+		c[k] = append([]CloudFrontHeader(nil), values...)
+	}
+
+	return c
+}
+
+func (h CloudFrontHeaders) size() int {
+	size := 0
+	for k, values := range h {
+		for _, v := range values {
+			size += len(k) + len(v.Value)
+		}
+	}
+
+	return size
+}
+
+func isCloudFrontHeaderEqual(a, b []CloudFrontHeader) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i].Value != b[i].Value {
+			return false
+		}
+	}
+
+	return true
+}
+
+func convertCloudFrontRequest(ctx context.Context, record CloudFrontRecord) (*http.Request, error) {
+	cfReq := record.Request
+
+	host := record.Config.DistributionDomainName
+	if values := cfReq.Headers["host"]; len(values) > 0 {
+		host = values[0].Value
+	}
+
+	var body io.Reader
+	if cfReq.Body != nil {
+		body = getBody(cfReq.Body.Data, cfReq.Body.Encoding == "base64")
+	}
+
+	url := buildFullRequestURL(host, cfReq.URI, "", cfReq.QueryString)
+	req, err := http.NewRequestWithContext(ctx, cfReq.Method, url, body)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, values := range cfReq.Headers {
+		for _, v := range values {
+			key := v.Key
+			if key == "" {
+				key = k
+			}
+
+			req.Header.Add(key, v.Value)
+		}
+	}
+
+	if values := cfReq.Headers["cloudfront-forwarded-proto"]; len(values) > 0 && strings.EqualFold(values[0].Value, "http") {
+		req.URL.Scheme = "http"
+	}
+
+	req.Host = host
+	req.TLS = newTLSConnectionState(req, "")
+	req = withSourceIP(req, cfReq.ClientIP)
+	req.RequestURI = req.URL.RequestURI()
+
+	return req, nil
+}
+
+func validateCloudFrontRequest(original, modified *CloudFrontRequest, limits cloudFrontLimits) error {
+	for _, k := range limits.readOnlyHeaders {
+		if !isCloudFrontHeaderEqual(original.Headers[k], modified.Headers[k]) {
+			return fmt.Errorf("%w: %s", ErrCloudFrontReadOnlyHeader, k)
+		}
+	}
+
+	if modified.Body != nil && modified.Body.Action == "replace" && len(modified.Body.Data) > limits.maxSize {
+		return ErrCloudFrontSizeLimitExceeded
+	}
+
+	return nil
+}
+
+func newCloudFrontResponse(statusCode int, headers http.Header, body []byte, o *options) CloudFrontResponse {
+	res := CloudFrontResponse{
+		Status:            strconv.Itoa(statusCode),
+		StatusDescription: http.StatusText(statusCode),
+		Headers:           make(CloudFrontHeaders),
+		BodyEncoding:      "text",
+	}
+
+	for k, values := range headers {
+		lk := strings.ToLower(k)
+
+		for _, v := range values {
+			res.Headers[lk] = append(res.Headers[lk], CloudFrontHeader{Key: k, Value: v})
+		}
+	}
+
+	var isB64 bool
+	if res.Body, isB64 = o.encodeBody(headers, body); isB64 {
+		res.BodyEncoding = "base64"
+	}
+
+	return res
+}
+
+func newCloudFrontResult(statusCode int, headers http.Header, body []byte, o *options) CloudFrontResult {
+	res := newCloudFrontResponse(statusCode, headers, body, o)
+	return CloudFrontResult{Response: &res}
+}
+
+func validateCloudFrontResponse(res CloudFrontResponse, limits cloudFrontLimits) error {
+	for k := range res.Headers {
+		for _, disallowed := range cloudFrontDisallowedResponseHeaders {
+			if k == disallowed {
+				return fmt.Errorf("%w: %s", ErrCloudFrontDisallowedHeader, k)
+			}
+		}
+
+		for _, prefix := range cloudFrontDisallowedResponseHeaderPrefixes {
+			if strings.HasPrefix(k, prefix) {
+				return fmt.Errorf("%w: %s", ErrCloudFrontDisallowedHeader, k)
+			}
+		}
+	}
+
+	if len(res.Body)+res.Headers.size() > limits.maxSize {
+		return ErrCloudFrontSizeLimitExceeded
+	}
+
+	return nil
+}
+
+func handleCloudFront(ctx context.Context, event CloudFrontEvent, adapter AdapterFunc, o *options) (CloudFrontResult, error) {
+	if len(event.Records) != 1 {
+		return respondError(ctx, o, newCloudFrontResult, ErrRequestConversion, fmt.Errorf("%w: expected exactly one record, got %d", ErrUnsupportedEventFormat, len(event.Records)))
+	}
+
+	record := event.Records[0].CF
+	limits, ok := cloudFrontEventTypeLimits[record.Config.EventType]
+	if !ok {
+		return respondError(ctx, o, newCloudFrontResult, ErrRequestConversion, fmt.Errorf("%w: %s", ErrCloudFrontUnsupportedTrigger, record.Config.EventType))
+	}
+
+	// the adapter may modify this copy of the request, which is passed through if no response was written
+	cfReq := record.Request
+	cfReq.Headers = cfReq.Headers.clone()
+	if cfReq.Body != nil {
+		body := *cfReq.Body
+		cfReq.Body = &body
+	}
+
+	ctx = context.WithValue(ctx, cloudFrontRequestContextKey, &cfReq)
+
+	req, err := convertCloudFrontRequest(ctx, record)
+	if err != nil {
+		return respondError(ctx, o, newCloudFrontResult, ErrRequestConversion, err)
+	}
+
+	o.resolveClientIP(req)
+
+	if err = o.decompressRequest(req); err != nil {
+		return respondError(ctx, o, newCloudFrontResult, ErrRequestConversion, err)
+	}
+
+	ctx = req.Context()
+
+	w := newBufferedResponseWriter()
+
+	if err = o.runAdapter(ctx, adapter, req, w); err != nil {
+		return respondError(ctx, o, newCloudFrontResult, ErrAdapter, err)
+	}
+
+	if !w.headersWritten {
+		if err = validateCloudFrontRequest(&record.Request, &cfReq, limits); err != nil {
+			return respondError(ctx, o, newCloudFrontResult, ErrAdapter, err)
+		}
+
+		return CloudFrontResult{Request: &cfReq}, nil
+	}
+
+	statusCode, headers, b, err := w.result(ctx, o)
+	if err != nil {
+		return respondError(ctx, o, newCloudFrontResult, ErrResponseBody, err)
+	}
+
+	result, err := finalizeResponse(ctx, o, newCloudFrontResult, w.encoding, statusCode, headers, b)
+	if err != nil || result.Response == nil {
+		return result, err
+	}
+
+	if err = validateCloudFrontResponse(*result.Response, limits); err != nil {
+		return respondError(ctx, o, newCloudFrontResult, ErrResponseBody, err)
+	}
+
+	return result, nil
+}
+
+// NewCloudFrontHandler creates a handler for Lambda@Edge viewer-request and origin-request events.
+// If the adapter writes a response, it is returned as a generated response.
+// Otherwise, the request (including modifications made using GetCloudFrontRequest) is passed through.
+func NewCloudFrontHandler(adapter AdapterFunc, opts ...Option) func(context.Context, CloudFrontEvent) (CloudFrontResult, error) {
+	return NewHandler(withOptions(handleCloudFront, newOptions(opts)), adapter)
+}
+
+// GetCloudFrontRequest returns the request which is passed through to the origin if the adapter doesn't write a response
+func GetCloudFrontRequest(ctx context.Context) (*CloudFrontRequest, bool) {
+	req, ok := ctx.Value(cloudFrontRequestContextKey).(*CloudFrontRequest)
+	return req, ok
+}
@@ -0,0 +1,237 @@
+//go:build !lambdahttpadapter.partial || (lambdahttpadapter.partial && lambdahttpadapter.cloudfront)
+
+package handler
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func newCloudFrontEvent(eventType string) CloudFrontEvent {
+	return CloudFrontEvent{
+		Records: []CloudFrontEventRecord{
+			{
+				CF: CloudFrontRecord{
+					Config: CloudFrontConfig{
+						DistributionDomainName: "d111111abcdef8.cloudfront.net",
+						DistributionID:         "EDFDVBD6EXAMPLE",
+						EventType:              eventType,
+						RequestID:              "4TyzHTaYWb1GX1qTfsHhEqV6HUDd_BzoBZnwfnvQc_1oF26ClkoUSEQ==",
+					},
+					Request: CloudFrontRequest{
+						ClientIP:    "203.0.113.178",
+						Method:      http.MethodPost,
+						URI:         "/example",
+						QueryString: "key=value",
+						Headers: CloudFrontHeaders{
+							"host":       {{Key: "Host", Value: "example.com"}},
+							"user-agent": {{Key: "User-Agent", Value: "curl/8.0.1"}},
+						},
+						Body: &CloudFrontRequestBody{
+							Action:   "read-only",
+							Data:     base64.StdEncoding.EncodeToString([]byte("hello world")),
+							Encoding: "base64",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestCloudFrontGeneratedResponse(t *testing.T) {
+	var caughtRequest *http.Request
+	var caughtBody []byte
+
+	h := NewCloudFrontHandler(func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+		caughtRequest = r
+		caughtBody, _ = io.ReadAll(r.Body)
+
+		w.Header().Set("Cache-Control", "max-age=100")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte("not found"))
+		return nil
+	})
+
+	res, err := h(context.Background(), newCloudFrontEvent(CloudFrontEventTypeViewerRequest))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if caughtRequest.URL.String() != "https://example.com/example?key=value" {
+		t.Errorf("unexpected url: %v", caughtRequest.URL.String())
+	}
+
+	if caughtRequest.RemoteAddr != "203.0.113.178:0" {
+		t.Errorf("unexpected remote addr: %v", caughtRequest.RemoteAddr)
+	}
+
+	if string(caughtBody) != "hello world" {
+		t.Errorf("unexpected body: %v", string(caughtBody))
+	}
+
+	if res.Request != nil || res.Response == nil {
+		t.Fatal("expected a generated response")
+	}
+
+	if res.Response.Status != "404" || res.Response.StatusDescription != "Not Found" {
+		t.Errorf("unexpected status: %v %v", res.Response.Status, res.Response.StatusDescription)
+	}
+
+	if v := res.Response.Headers["cache-control"]; len(v) != 1 || v[0].Key != "Cache-Control" || v[0].Value != "max-age=100" {
+		t.Errorf("unexpected headers: %v", res.Response.Headers)
+	}
+
+	if res.Response.Body != "not found" || res.Response.BodyEncoding != "text" {
+		t.Error("unexpected body")
+	}
+
+	b, _ := json.Marshal(res)
+	if !strings.Contains(string(b), `"status":"404"`) {
+		t.Errorf("expected the response to be serialized, got %v", string(b))
+	}
+}
+
+func TestCloudFrontPassThrough(t *testing.T) {
+	h := NewCloudFrontHandler(func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+		cfReq, ok := GetCloudFrontRequest(ctx)
+		if !ok {
+			t.Fatal("expected the request to be present")
+		}
+
+		cfReq.URI = "/rewritten"
+		cfReq.Headers["x-example"] = []CloudFrontHeader{{Key: "X-Example", Value: "value"}}
+		return nil
+	})
+
+	event := newCloudFrontEvent(CloudFrontEventTypeOriginRequest)
+	res, err := h(context.Background(), event)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Response != nil || res.Request == nil {
+		t.Fatal("expected the request to be passed through")
+	}
+
+	if res.Request.URI != "/rewritten" || res.Request.Headers["x-example"][0].Value != "value" {
+		t.Errorf("expected modifications to be present: %v", res.Request)
+	}
+
+	if _, ok := event.Records[0].CF.Request.Headers["x-example"]; ok {
+		t.Error("expected the source event not to be modified")
+	}
+}
+
+func TestCloudFrontReadOnlyHeader(t *testing.T) {
+	h := NewCloudFrontHandler(func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+		cfReq, _ := GetCloudFrontRequest(ctx)
+		cfReq.Headers["host"] = []CloudFrontHeader{{Key: "Host", Value: "other.example.com"}}
+		return nil
+	})
+
+	_, err := h(context.Background(), newCloudFrontEvent(CloudFrontEventTypeViewerRequest))
+	if !errors.Is(err, ErrCloudFrontReadOnlyHeader) {
+		t.Errorf("expected ErrCloudFrontReadOnlyHeader, got %v", err)
+	}
+}
+
+func TestCloudFrontSizeLimit(t *testing.T) {
+	h := NewCloudFrontHandler(func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+		_, _ = w.Write([]byte(strings.Repeat("a", 40*1024)))
+		return nil
+	})
+
+	_, err := h(context.Background(), newCloudFrontEvent(CloudFrontEventTypeViewerRequest))
+	if !errors.Is(err, ErrCloudFrontSizeLimitExceeded) {
+		t.Errorf("expected ErrCloudFrontSizeLimitExceeded, got %v", err)
+	}
+
+	_, err = h(context.Background(), newCloudFrontEvent(CloudFrontEventTypeOriginRequest))
+	if err != nil {
+		t.Errorf("expected origin-request limit not to be exceeded, got %v", err)
+	}
+}
+
+func TestCloudFrontDisallowedHeader(t *testing.T) {
+	h := NewCloudFrontHandler(func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+		w.Header().Set("X-Cache", "Hit")
+		_, _ = w.Write([]byte("hello world"))
+		return nil
+	})
+
+	_, err := h(context.Background(), newCloudFrontEvent(CloudFrontEventTypeViewerRequest))
+	if !errors.Is(err, ErrCloudFrontDisallowedHeader) {
+		t.Errorf("expected ErrCloudFrontDisallowedHeader, got %v", err)
+	}
+}
+
+func TestCloudFrontValidationErrorResponder(t *testing.T) {
+	tests := map[string]struct {
+		adapter AdapterFunc
+		status  string
+	}{
+		"request": {
+			func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+				cfReq, _ := GetCloudFrontRequest(ctx)
+				cfReq.Headers["host"] = []CloudFrontHeader{{Key: "Host", Value: "other.example.com"}}
+				return nil
+			},
+			"500",
+		},
+		"response": {
+			func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+				w.Header().Set("X-Cache", "Hit")
+				_, _ = w.Write([]byte("hello world"))
+				return nil
+			},
+			"500",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			h := NewCloudFrontHandler(tt.adapter, WithErrorResponder(ProblemDetailsErrorResponder))
+
+			res, err := h(context.Background(), newCloudFrontEvent(CloudFrontEventTypeViewerRequest))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if res.Response == nil || res.Response.Status != tt.status {
+				t.Errorf("expected a generated response with status %s, got %+v", tt.status, res)
+			}
+		})
+	}
+}
+
+func TestCloudFrontRequestIDHeaders(t *testing.T) {
+	h := NewCloudFrontHandler(func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+		_, _ = w.Write([]byte("hello world"))
+		return nil
+	}, WithRequestIDHeaders())
+
+	ctx := lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{
+		AwsRequestID: "c6af9ac6-7b61-11e6-9a41-93e8deadbeef",
+	})
+
+	res, err := h(ctx, newCloudFrontEvent(CloudFrontEventTypeViewerRequest))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := res.Response.Headers["x-amzn-requestid"]; ok {
+		t.Error("expected X-Amzn-RequestId not to be added")
+	}
+
+	if len(res.Response.Headers["x-request-id"]) != 1 {
+		t.Errorf("expected X-Request-Id to be added, got %v", res.Response.Headers)
+	}
+}
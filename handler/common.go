@@ -1,10 +1,23 @@
 package handler
 
 import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
 	"io"
+	"net"
+	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
 )
 
 func buildQuery(rawQuery string, queryParams map[string]string) string {
@@ -17,7 +30,7 @@ func buildQuery(rawQuery string, queryParams map[string]string) string {
 			q.Add(k, v)
 		}
 
-		return "?" + q.Encode()
+		return q.Encode()
 	}
 
 	return ""
@@ -43,6 +56,43 @@ func buildFullRequestURL(host string, path string, altPath string, query string)
 	return rUrl
 }
 
+// setHost sets the host of the request to the domain name of the event or, if not present, to the Host header
+func setHost(req *http.Request, domainName string) {
+	host := domainName
+	if host == "" {
+		host = req.Header.Get("Host")
+	}
+
+	req.Host = host
+	req.URL.Host = host
+}
+
+// newTLSConnectionState returns a synthetic connection state, TLS was already terminated before the event was sent to Lambda.
+// clientCertPEM is the PEM encoded client certificate if mTLS was used.
+func newTLSConnectionState(req *http.Request, clientCertPEM string) *tls.ConnectionState {
+	if req.URL.Scheme != "https" {
+		return nil
+	}
+
+	serverName := req.Host
+	if h, _, err := net.SplitHostPort(serverName); err == nil {
+		serverName = h
+	}
+
+	cs := &tls.ConnectionState{
+		HandshakeComplete: true,
+		ServerName:        serverName,
+	}
+
+	if block, _ := pem.Decode([]byte(clientCertPEM)); block != nil {
+		if cert, err := x509.ParseCertificate(block.Bytes); err == nil {
+			cs.PeerCertificates = []*x509.Certificate{cert}
+		}
+	}
+
+	return cs
+}
+
 func getBody(body string, isB64 bool) io.Reader {
 	if body == "" {
 		return nil
@@ -57,3 +107,401 @@ func getBody(body string, isB64 bool) io.Reader {
 
 	return b
 }
+
+// Option configures the behavior of a handler.
+// Options which don't apply to an event format are ignored by its handler.
+type Option func(*options)
+
+type options struct {
+	isBase64         func(header http.Header, body []byte) bool
+	sniffContentType bool
+	setContentLength bool
+	foldHeader       func(key string, values []string) string
+	errorResponder   ErrorResponder
+	stripStage       bool
+	basePath         string
+	forwardedHeader  ForwardedHeader
+	trustedHops      int
+	requestIDHeaders bool
+	timeoutMargin    time.Duration
+	timeoutResponder ErrorResponder
+
+	maxResponseSize            int
+	oversizedResponseHook      OversizedResponseHook
+	truncateOversizedResponses bool
+
+	compression                *compressionOptions
+	maxDecompressedRequestSize int64
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		isBase64: func(header http.Header, body []byte) bool {
+			return !utf8.Valid(body)
+		},
+		sniffContentType: true,
+		setContentLength: true,
+		foldHeader: func(key string, values []string) string {
+			return strings.Join(values, ",")
+		},
+		maxResponseSize: DefaultMaxResponseSize,
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithBinaryMediaTypes base64-encodes response bodies if their Content-Type matches one of the given media types.
+// Media types may contain wildcards, for example "image/*" or "*/*".
+// Bodies which are not valid UTF-8 are always base64-encoded.
+func WithBinaryMediaTypes(mediaTypes ...string) Option {
+	return func(o *options) {
+		o.isBase64 = func(header http.Header, body []byte) bool {
+			return isBinaryMediaType(header.Get("Content-Type"), mediaTypes) || !utf8.Valid(body)
+		}
+	}
+}
+
+// WithAlwaysBase64 base64-encodes all response bodies
+func WithAlwaysBase64() Option {
+	return func(o *options) {
+		o.isBase64 = func(header http.Header, body []byte) bool {
+			return true
+		}
+	}
+}
+
+// WithBase64Predicate uses the given predicate to decide whether a response body is base64-encoded
+func WithBase64Predicate(isBase64 func(header http.Header, body []byte) bool) Option {
+	return func(o *options) {
+		o.isBase64 = isBase64
+	}
+}
+
+// WithContentTypeSniffing controls whether the Content-Type of buffered responses is detected using http.DetectContentType if it was not set by the adapter (enabled by default)
+func WithContentTypeSniffing(enabled bool) Option {
+	return func(o *options) {
+		o.sniffContentType = enabled
+	}
+}
+
+// WithContentLength controls whether the Content-Length header is added to buffered responses if it was not set by the adapter (enabled by default)
+func WithContentLength(enabled bool) Option {
+	return func(o *options) {
+		o.setContentLength = enabled
+	}
+}
+
+// WithHeaderFolding sets the function used to fold multiple values of a header into one for event formats which only support a single value per header.
+// By default, the values are joined using a comma.
+func WithHeaderFolding(fold func(key string, values []string) string) Option {
+	return func(o *options) {
+		o.foldHeader = fold
+	}
+}
+
+// WithStripStage removes the stage (for example /prod) from the beginning of the request path for API Gateway V1 and V2.
+// The original path is available using GetOriginalPath.
+func WithStripStage() Option {
+	return func(o *options) {
+		o.stripStage = true
+	}
+}
+
+// WithStripBasePath removes the given base path (for example the base path mapping of a custom domain) from the beginning of the request path for API Gateway V1 and V2.
+// The original path is available using GetOriginalPath.
+func WithStripBasePath(basePath string) Option {
+	return func(o *options) {
+		o.basePath = "/" + strings.Trim(basePath, "/")
+		if o.basePath == "/" {
+			o.basePath = ""
+		}
+	}
+}
+
+func isBinaryMediaType(contentType string, mediaTypes []string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	if mediaType == "" {
+		return false
+	}
+
+	typ, subtype, _ := strings.Cut(mediaType, "/")
+
+	for _, candidate := range mediaTypes {
+		cTyp, cSubtype, _ := strings.Cut(strings.ToLower(candidate), "/")
+
+		if (cTyp == "*" || cTyp == typ) && (cSubtype == "*" || cSubtype == subtype) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (o *options) encodeBody(header http.Header, body []byte) (string, bool) {
+	if o.isBase64(header, body) {
+		return base64.StdEncoding.EncodeToString(body), true
+	}
+
+	return string(body), false
+}
+
+type optionsHandlerFunc[In any, Out any] func(ctx context.Context, event In, adapter AdapterFunc, o *options) (Out, error)
+
+func withOptions[In any, Out any](handlerFunc optionsHandlerFunc[In, Out], o *options) HandlerFunc[In, Out] {
+	return func(ctx context.Context, event In, adapter AdapterFunc) (Out, error) {
+		return handlerFunc(ctx, event, adapter, o)
+	}
+}
+
+// bufferedResponseWriter collects the complete response written by the adapter
+type bufferedResponseWriter struct {
+	mu             sync.Mutex
+	timeoutCtx     context.Context
+	timedOut       bool
+	maxSize        int
+	failFast       bool
+	encoding       string
+	headerSize     int
+	tooLarge       int
+	headersWritten bool
+	statusCode     int
+	headers        http.Header
+	writtenHeaders http.Header
+	body           bytes.Buffer
+}
+
+func newBufferedResponseWriter() *bufferedResponseWriter {
+	return &bufferedResponseWriter{
+		headers: make(http.Header),
+	}
+}
+
+func (w *bufferedResponseWriter) Header() http.Header {
+	return w.headers
+}
+
+func (w *bufferedResponseWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.timedOut || timeoutReached(w.timeoutCtx) {
+		return 0, http.ErrHandlerTimeout
+	}
+
+	w.writeHeader(http.StatusOK)
+
+	if w.maxSize > 0 {
+		if size := w.headerSize + w.body.Len() + len(p); size > w.maxSize {
+			if w.failFast {
+				w.tooLarge = size
+				return 0, newResponseTooLargeError(size, w.maxSize)
+			}
+
+			// the response is truncated anyway, so the rest doesn't need to be buffered
+			if keep := w.maxSize - w.headerSize - w.body.Len(); keep > 0 {
+				_, _ = w.body.Write(p[:keep])
+			}
+
+			return len(p), nil
+		}
+	}
+
+	return w.body.Write(p)
+}
+
+func (w *bufferedResponseWriter) WriteHeader(statusCode int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.writeHeader(statusCode)
+}
+
+func (w *bufferedResponseWriter) setTimeoutContext(ctx context.Context) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.timeoutCtx = ctx
+}
+
+// prepare applies the options which depend on the request before the adapter writes the response.
+// The maximum response size is applied while writing, unless the body is buffered completely for WithOversizedResponseHook or WithCompression.
+func (w *bufferedResponseWriter) prepare(req *http.Request, o *options) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.encoding = o.negotiateEncoding(req)
+
+	if o.maxResponseSize > 0 && o.oversizedResponseHook == nil && w.encoding == "" {
+		w.maxSize = o.maxResponseSize
+		w.failFast = !o.truncateOversizedResponses
+	}
+}
+
+// timeout makes all further writes fail, the response is discarded
+func (w *bufferedResponseWriter) timeout() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.timedOut = true
+}
+
+func (w *bufferedResponseWriter) writeHeader(statusCode int) {
+	if !w.headersWritten {
+		w.headersWritten = true
+		w.statusCode = statusCode
+		w.writtenHeaders = w.headers.Clone()
+		w.headerSize = minSerializedSize(w.writtenHeaders, 0)
+	}
+}
+
+// result returns the status code, headers and body of the response.
+// Depending on the options, Content-Type, Content-Length and the request ID headers are added if they were not set by the adapter.
+// The body is compressed by finalizeResponse, once the size of the response is known.
+func (w *bufferedResponseWriter) result(ctx context.Context, o *options) (int, http.Header, []byte, error) {
+	w.WriteHeader(http.StatusOK)
+
+	if w.tooLarge > 0 {
+		return 0, nil, nil, newResponseTooLargeError(w.tooLarge, w.maxSize)
+	}
+
+	b, err := io.ReadAll(&w.body)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	headers := w.writtenHeaders
+	o.setRequestIDHeaders(ctx, headers)
+
+	if o.sniffContentType && !hasHeader(headers, "Content-Type") {
+		headers.Set("Content-Type", http.DetectContentType(b))
+	}
+
+	if o.setContentLength && !hasHeader(headers, "Content-Length") {
+		headers.Set("Content-Length", strconv.Itoa(len(b)))
+	}
+
+	return w.statusCode, headers, b, nil
+}
+
+func hasHeader(headers http.Header, key string) bool {
+	for k := range headers {
+		if strings.EqualFold(k, key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+var (
+	ErrRequestConversion = errors.New("failed to convert the event to a request")
+	ErrAdapter           = errors.New("adapter failed to process the request")
+	ErrResponseBody      = errors.New("failed to read the response body")
+)
+
+// ErrorResponder maps an error which occurred while handling an event to a response.
+// The error matches one of ErrRequestConversion, ErrAdapter, ErrResponseBody, ErrResponseTooLarge or ErrTimeout using errors.Is and wraps the original error,
+// which matches ErrRequestBodyTooLarge if a decompressed request body exceeds the limit of WithRequestDecompression.
+type ErrorResponder func(ctx context.Context, event any, err error) (statusCode int, headers http.Header, body []byte)
+
+// WithErrorResponder makes the handler respond using the given ErrorResponder instead of failing the Lambda invocation if an error occurs
+func WithErrorResponder(responder ErrorResponder) Option {
+	return func(o *options) {
+		o.errorResponder = responder
+	}
+}
+
+// ProblemDetails is the RFC 9457 problem details object
+type ProblemDetails struct {
+	Type     string `json:"type,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Status   int    `json:"status,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+}
+
+// ProblemDetailsErrorResponder responds with an RFC 9457 application/problem+json body.
+// Errors converting the event respond with 400 Bad Request, request bodies exceeding the maximum size with 413 Content Too Large, timeouts with 504 Gateway Timeout,
+// responses exceeding the maximum size with 502 Bad Gateway, all others with 500 Internal Server Error.
+// The original error is not exposed to the client.
+func ProblemDetailsErrorResponder(ctx context.Context, event any, err error) (int, http.Header, []byte) {
+	statusCode := http.StatusInternalServerError
+	detail := "the request could not be processed"
+
+	if errors.Is(err, ErrRequestBodyTooLarge) {
+		statusCode = http.StatusRequestEntityTooLarge
+		detail = ErrRequestBodyTooLarge.Error()
+	} else if errors.Is(err, ErrRequestConversion) {
+		statusCode = http.StatusBadRequest
+		detail = ErrRequestConversion.Error()
+	} else if errors.Is(err, ErrTimeout) {
+		statusCode = http.StatusGatewayTimeout
+		detail = ErrTimeout.Error()
+	} else if errors.Is(err, ErrResponseTooLarge) {
+		statusCode = http.StatusBadGateway
+		detail = ErrResponseTooLarge.Error()
+	}
+
+	body, _ := json.Marshal(ProblemDetails{
+		Type:   "about:blank",
+		Title:  http.StatusText(statusCode),
+		Status: statusCode,
+		Detail: detail,
+	})
+
+	headers := make(http.Header)
+	headers.Set("Content-Type", "application/problem+json")
+	headers.Set("Content-Length", strconv.Itoa(len(body)))
+
+	return statusCode, headers, body
+}
+
+type handlerError struct {
+	kind error
+	err  error
+}
+
+func (e *handlerError) Error() string {
+	return e.kind.Error() + ": " + e.err.Error()
+}
+
+func (e *handlerError) Unwrap() error {
+	return e.err
+}
+
+func (e *handlerError) Is(target error) bool {
+	return target == e.kind
+}
+
+// respondError returns the response of the configured ErrorResponder, or the error itself if none is configured.
+// Errors which were already classified (for example timeouts) keep their kind, timeouts are passed to the ErrorResponder of WithTimeout.
+func respondError[Out any](ctx context.Context, o *options, newResponse func(statusCode int, headers http.Header, body []byte, o *options) Out, kind error, err error) (Out, error) {
+	var he *handlerError
+	if !errors.As(err, &he) {
+		he = &handlerError{kind: kind, err: err}
+	}
+
+	responder := o.errorResponder
+	if he.kind == ErrTimeout {
+		responder = o.timeoutResponder
+	}
+
+	if responder == nil {
+		var def Out
+		return def, err
+	}
+
+	statusCode, headers, body := responder(ctx, GetSourceEvent(ctx), he)
+	if headers == nil {
+		headers = make(http.Header)
+	}
+
+	o.setRequestIDHeaders(ctx, headers)
+
+	return newResponse(statusCode, headers, body, o), nil
+}
@@ -0,0 +1,352 @@
+//go:build !lambdahttpadapter.partial || (lambdahttpadapter.partial && lambdahttpadapter.alb && lambdahttpadapter.apigwv1 && lambdahttpadapter.apigwv2 && lambdahttpadapter.functionurl)
+
+package handler
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"github.com/aws/aws-lambda-go/events"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsBinaryMediaType(t *testing.T) {
+	tests := []struct {
+		contentType string
+		mediaTypes  []string
+		expected    bool
+	}{
+		{"image/png", []string{"image/png"}, true},
+		{"image/png", []string{"image/*"}, true},
+		{"IMAGE/PNG", []string{"image/*"}, true},
+		{"application/x-protobuf; proto=example", []string{"application/x-protobuf"}, true},
+		{"application/json", []string{"*/*"}, true},
+		{"application/json", []string{"image/*", "application/octet-stream"}, false},
+		{"", []string{"*/*"}, false},
+	}
+
+	for _, tt := range tests {
+		if actual := isBinaryMediaType(tt.contentType, tt.mediaTypes); actual != tt.expected {
+			t.Errorf("isBinaryMediaType(%q, %v): expected %v, got %v", tt.contentType, tt.mediaTypes, tt.expected, actual)
+		}
+	}
+}
+
+func TestBuildFullRequestURLQuery(t *testing.T) {
+	tests := []struct {
+		rawQuery    string
+		queryParams map[string]string
+		expected    string
+	}{
+		{"a=1&b=2", nil, "https://example.com/path?a=1&b=2"},
+		{"", map[string]string{"a": "1", "b": "x y"}, "https://example.com/path?a=1&b=x+y"},
+		{"", nil, "https://example.com/path"},
+	}
+
+	for _, tt := range tests {
+		if actual := buildFullRequestURL("example.com", "/path", "", buildQuery(tt.rawQuery, tt.queryParams)); actual != tt.expected {
+			t.Errorf("buildFullRequestURL(%q, %v) = %q, expected %q", tt.rawQuery, tt.queryParams, actual, tt.expected)
+		}
+	}
+}
+
+func TestBase64Options(t *testing.T) {
+	// valid UTF-8, but binary according to its Content-Type
+	body := []byte("GIF89a")
+	adapter := func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+		w.Header().Set("Content-Type", r.URL.Query().Get("ct"))
+		_, _ = w.Write(body)
+		return nil
+	}
+
+	tests := map[string]struct {
+		opts     []Option
+		ct       string
+		expected bool
+	}{
+		"default":                   {nil, "image/gif", false},
+		"binary media type":         {[]Option{WithBinaryMediaTypes("image/*")}, "image/gif", true},
+		"binary media type nomatch": {[]Option{WithBinaryMediaTypes("image/*")}, "text/plain", false},
+		"always":                    {[]Option{WithAlwaysBase64()}, "text/plain", true},
+		"predicate": {
+			[]Option{WithBase64Predicate(func(header http.Header, body []byte) bool {
+				return header.Get("Content-Type") == "application/x-protobuf"
+			})},
+			"application/x-protobuf",
+			true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			h := NewAPIGatewayV1Handler(adapter, tt.opts...)
+			res, err := h(context.Background(), events.APIGatewayProxyRequest{
+				HTTPMethod:            http.MethodGet,
+				Path:                  "/",
+				QueryStringParameters: map[string]string{"ct": tt.ct},
+			})
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if res.IsBase64Encoded != tt.expected {
+				t.Errorf("expected IsBase64Encoded to be %v", tt.expected)
+			}
+
+			expectedBody := string(body)
+			if tt.expected {
+				expectedBody = base64.StdEncoding.EncodeToString(body)
+			}
+
+			if res.Body != expectedBody {
+				t.Errorf("unexpected body: %q", res.Body)
+			}
+
+			if res.Headers["Content-Type"] != tt.ct {
+				t.Errorf("expected Content-Type set by the adapter to be kept, got %q", res.Headers["Content-Type"])
+			}
+		})
+	}
+}
+
+func TestResponseOptions(t *testing.T) {
+	adapter := func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+		w.Header().Add("X-Example", "a")
+		w.Header().Add("X-Example", "b")
+		_, _ = w.Write([]byte("hello world"))
+		return nil
+	}
+
+	event := events.LambdaFunctionURLRequest{
+		RequestContext: events.LambdaFunctionURLRequestContext{
+			HTTP: events.LambdaFunctionURLRequestContextHTTPDescription{
+				Method: http.MethodGet,
+			},
+		},
+	}
+
+	res, err := NewFunctionURLHandler(adapter)(context.Background(), event)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Headers["X-Example"] != "a,b" || res.Headers["Content-Type"] == "" || res.Headers["Content-Length"] != "11" {
+		t.Errorf("unexpected default headers: %v", res.Headers)
+	}
+
+	h := NewFunctionURLHandler(
+		adapter,
+		WithContentTypeSniffing(false),
+		WithContentLength(false),
+		WithHeaderFolding(func(key string, values []string) string {
+			return values[len(values)-1]
+		}),
+	)
+
+	res, err = h(context.Background(), event)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := res.Headers["Content-Type"]; ok {
+		t.Error("expected Content-Type not to be set")
+	}
+
+	if _, ok := res.Headers["Content-Length"]; ok {
+		t.Error("expected Content-Length not to be set")
+	}
+
+	if res.Headers["X-Example"] != "b" {
+		t.Errorf("expected custom header folding to be used, got %q", res.Headers["X-Example"])
+	}
+}
+
+func TestErrorResponder(t *testing.T) {
+	adapterErr := errors.New("something went wrong")
+	adapter := func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+		return adapterErr
+	}
+
+	event := events.APIGatewayProxyRequest{
+		HTTPMethod: http.MethodGet,
+		Path:       "/",
+	}
+
+	if _, err := NewAPIGatewayV1Handler(adapter)(context.Background(), event); err != adapterErr {
+		t.Fatalf("expected the error to be returned by default, got %v", err)
+	}
+
+	var caughtEvent any
+	var caughtErr error
+	h := NewAPIGatewayV1Handler(adapter, WithErrorResponder(func(ctx context.Context, event any, err error) (int, http.Header, []byte) {
+		caughtEvent = event
+		caughtErr = err
+		return ProblemDetailsErrorResponder(ctx, event, err)
+	}))
+
+	res, err := h(context.Background(), event)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := caughtEvent.(events.APIGatewayProxyRequest); !ok {
+		t.Errorf("expected the source event to be passed, got %T", caughtEvent)
+	}
+
+	if !errors.Is(caughtErr, ErrAdapter) || !errors.Is(caughtErr, adapterErr) {
+		t.Errorf("expected the error to match ErrAdapter and the original error, got %v", caughtErr)
+	}
+
+	if res.StatusCode != http.StatusInternalServerError || res.Headers["Content-Type"] != "application/problem+json" {
+		t.Errorf("unexpected response: %v %v", res.StatusCode, res.Headers)
+	}
+
+	var problem ProblemDetails
+	if err = json.Unmarshal([]byte(res.Body), &problem); err != nil {
+		t.Fatal(err)
+	}
+
+	if problem.Status != http.StatusInternalServerError || problem.Title != "Internal Server Error" {
+		t.Errorf("unexpected problem details: %+v", problem)
+	}
+
+	if strings.Contains(res.Body, adapterErr.Error()) {
+		t.Error("expected the original error not to be exposed")
+	}
+}
+
+func TestErrorResponderRequestConversion(t *testing.T) {
+	h := NewFunctionURLStreamingHandler(func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+		return nil
+	}, WithErrorResponder(ProblemDetailsErrorResponder))
+
+	res, err := h(context.Background(), events.LambdaFunctionURLRequest{
+		RequestContext: events.LambdaFunctionURLRequestContext{
+			HTTP: events.LambdaFunctionURLRequestContextHTTPDescription{
+				Method: "INVALID METHOD",
+			},
+		},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.StatusCode != http.StatusBadRequest {
+		t.Errorf("unexpected status: %v", res.StatusCode)
+	}
+}
+
+func newTestCertificatePEM(t *testing.T) string {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "client.example.com"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+func TestHostAndTLS(t *testing.T) {
+	var caught *http.Request
+	adapter := func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+		caught = r
+		return nil
+	}
+
+	certPEM := newTestCertificatePEM(t)
+
+	v2Event := events.APIGatewayV2HTTPRequest{
+		RawPath: "/",
+		Headers: map[string]string{"host": "other.example.com"},
+		RequestContext: events.APIGatewayV2HTTPRequestContext{
+			DomainName: "api.example.com",
+			HTTP: events.APIGatewayV2HTTPRequestContextHTTPDescription{
+				Method: http.MethodGet,
+			},
+		},
+	}
+	v2Event.RequestContext.Authentication.ClientCert.ClientCertPem = certPEM
+
+	if _, err := NewAPIGatewayV2Handler(adapter)(context.Background(), v2Event); err != nil {
+		t.Fatal(err)
+	}
+
+	if caught.Host != "api.example.com" || caught.TLS == nil || caught.TLS.ServerName != "api.example.com" {
+		t.Errorf("unexpected host or TLS state: %v %+v", caught.Host, caught.TLS)
+	}
+
+	if len(caught.TLS.PeerCertificates) != 1 || caught.TLS.PeerCertificates[0].Subject.CommonName != "client.example.com" {
+		t.Error("expected the client certificate to be present")
+	}
+
+	_, err := NewFunctionURLHandler(adapter)(context.Background(), events.LambdaFunctionURLRequest{
+		Headers: map[string]string{"host": "fn.example.com"},
+		RequestContext: events.LambdaFunctionURLRequestContext{
+			HTTP: events.LambdaFunctionURLRequestContextHTTPDescription{
+				Method: http.MethodGet,
+			},
+		},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if caught.Host != "fn.example.com" || caught.URL.Host != "fn.example.com" || caught.TLS == nil || len(caught.TLS.PeerCertificates) != 0 {
+		t.Errorf("expected the Host header to be used if the domain name is missing: %v %+v", caught.Host, caught.TLS)
+	}
+
+	albEvent := events.ALBTargetGroupRequest{
+		HTTPMethod: http.MethodGet,
+		Path:       "/",
+		Headers: map[string]string{
+			"host":                   "alb.example.com",
+			"x-forwarded-proto":      "https",
+			"x-amzn-mtls-clientcert": url.PathEscape(certPEM),
+		},
+	}
+
+	if _, err = NewALBHandler(adapter)(context.Background(), albEvent); err != nil {
+		t.Fatal(err)
+	}
+
+	if caught.Host != "alb.example.com" || caught.TLS == nil || len(caught.TLS.PeerCertificates) != 1 {
+		t.Errorf("expected the ALB client certificate to be present: %+v", caught.TLS)
+	}
+
+	albEvent.Headers["x-forwarded-proto"] = "http"
+	if _, err = NewALBHandler(adapter)(context.Background(), albEvent); err != nil {
+		t.Fatal(err)
+	}
+
+	if caught.TLS != nil {
+		t.Error("expected no TLS state for plain HTTP requests")
+	}
+}
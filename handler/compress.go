@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"bytes"
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// DefaultCompressibleContentTypes are the content types compressed by WithCompression if none are given
+var DefaultCompressibleContentTypes = []string{
+	"text/*",
+	"application/json",
+	"application/problem+json",
+	"application/javascript",
+	"application/xml",
+	"image/svg+xml",
+}
+
+// supportedEncodings in order of preference if the client accepts several with the same quality
+var supportedEncodings = []string{"br", "zstd", "gzip"}
+
+// WithCompression compresses responses using brotli, zstd or gzip if the Accept-Encoding header of the request allows it.
+// Only responses with a Content-Type matching one of contentTypes (DefaultCompressibleContentTypes if empty) and a body of at least minSize bytes are compressed.
+// The size of streaming responses is only known if the adapter sets Content-Length, otherwise they are compressed chunk by chunk regardless of their size.
+// Responses which already have a Content-Encoding or a Cache-Control: no-transform header are never compressed.
+func WithCompression(minSize int, contentTypes ...string) Option {
+	if len(contentTypes) == 0 {
+		contentTypes = DefaultCompressibleContentTypes
+	}
+
+	return func(o *options) {
+		o.compression = &compressionOptions{
+			minSize:      minSize,
+			contentTypes: contentTypes,
+		}
+	}
+}
+
+type compressionOptions struct {
+	minSize      int
+	contentTypes []string
+}
+
+// negotiateEncoding returns the preferred encoding accepted by the request, or an empty string if compression is disabled or none is accepted
+func (o *options) negotiateEncoding(req *http.Request) string {
+	if o.compression == nil {
+		return ""
+	}
+
+	return negotiateEncoding(req.Header.Values("Accept-Encoding"))
+}
+
+func negotiateEncoding(acceptEncoding []string) string {
+	qualities := make(map[string]float64)
+	for _, v := range acceptEncoding {
+		for _, part := range strings.Split(v, ",") {
+			coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+			coding = strings.ToLower(strings.TrimSpace(coding))
+			if coding == "" {
+				continue
+			}
+
+			q := 1.0
+			if k, v, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(k) == "q" {
+				if parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+					q = parsed
+				}
+			}
+
+			qualities[coding] = q
+		}
+	}
+
+	best, bestQ := "", 0.0
+	for _, coding := range supportedEncodings {
+		q, ok := qualities[coding]
+		if !ok {
+			q = qualities["*"]
+		}
+
+		if q > bestQ {
+			best, bestQ = coding, q
+		}
+	}
+
+	return best
+}
+
+// shouldCompress reports whether a response with the given headers is compressed, size is -1 if unknown.
+// Vary: Accept-Encoding is added to all responses which are eligible for compression.
+func (o *options) shouldCompress(encoding string, statusCode int, headers http.Header, size int) bool {
+	if o.compression == nil || statusCode == http.StatusNoContent || statusCode == http.StatusNotModified || statusCode == http.StatusPartialContent {
+		return false
+	}
+
+	// the encoded body of a range wouldn't match the requested bytes
+	if hasHeader(headers, "Content-Encoding") || hasHeader(headers, "Content-Range") || strings.Contains(strings.ToLower(headers.Get("Cache-Control")), "no-transform") {
+		return false
+	}
+
+	if !isBinaryMediaType(headers.Get("Content-Type"), o.compression.contentTypes) {
+		return false
+	}
+
+	addVary(headers, "Accept-Encoding")
+
+	return encoding != "" && (size < 0 || size >= o.compression.minSize)
+}
+
+func addVary(headers http.Header, value string) {
+	for _, v := range headers.Values("Vary") {
+		for _, part := range strings.Split(v, ",") {
+			if p := strings.TrimSpace(part); p == "*" || strings.EqualFold(p, value) {
+				return
+			}
+		}
+	}
+
+	headers.Add("Vary", value)
+}
+
+// encoder is implemented by the writers of all supported encodings
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+}
+
+func newEncoder(encoding string, w io.Writer) (encoder, error) {
+	switch encoding {
+	case "br":
+		return brotli.NewWriterLevel(w, brotli.DefaultCompression), nil
+	case "zstd":
+		return zstd.NewWriter(w, zstd.WithEncoderConcurrency(1))
+	default:
+		return gzip.NewWriter(w), nil
+	}
+}
+
+// setContentEncoding sets Content-Encoding and weakens a strong ETag, which no longer matches the encoded body byte for byte
+func setContentEncoding(headers http.Header, encoding string) {
+	headers.Set("Content-Encoding", encoding)
+	if etag := headers.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		headers.Set("ETag", "W/"+etag)
+	}
+}
+
+// compress compresses the body and updates Content-Encoding and Content-Length
+func compress(encoding string, headers http.Header, body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	enc, err := newEncoder(encoding, &buf)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = enc.Write(body); err != nil {
+		return nil, err
+	}
+
+	if err = enc.Close(); err != nil {
+		return nil, err
+	}
+
+	setContentEncoding(headers, encoding)
+	if hasHeader(headers, "Content-Length") {
+		headers.Set("Content-Length", strconv.Itoa(buf.Len()))
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"testing"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := map[string]string{
+		"":                        "",
+		"identity":                "",
+		"gzip":                    "gzip",
+		"gzip, deflate, br":       "br",
+		"gzip;q=1.0, br;q=0.5":    "gzip",
+		"zstd, gzip":              "zstd",
+		"*":                       "br",
+		"*, br;q=0":               "zstd",
+		"GZIP;q=0.8, deflate":     "gzip",
+		"gzip;q=0, deflate;q=0.5": "",
+	}
+
+	for acceptEncoding, expected := range tests {
+		if actual := negotiateEncoding([]string{acceptEncoding}); actual != expected {
+			t.Errorf("%q: expected %q, got %q", acceptEncoding, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+//go:build !lambdahttpadapter.partial || (lambdahttpadapter.partial && lambdahttpadapter.apigwv2 && lambdahttpadapter.functionurl)
+
+package handler_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"github.com/andybalholm/brotli"
+	"github.com/its-felix/aws-lambda-go-http-adapter/handler"
+	"github.com/its-felix/aws-lambda-go-http-adapter/handlertest"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func decompress(t *testing.T, encoding string, body []byte) string {
+	t.Helper()
+
+	var r io.Reader
+	var err error
+
+	switch encoding {
+	case "gzip":
+		r, err = gzip.NewReader(bytes.NewReader(body))
+	case "br":
+		r = brotli.NewReader(bytes.NewReader(body))
+	case "zstd":
+		r, err = zstd.NewReader(bytes.NewReader(body))
+	default:
+		t.Fatalf("unexpected encoding %q", encoding)
+	}
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(b)
+}
+
+func TestCompressionBuffered(t *testing.T) {
+	body := strings.Repeat(`{"hello":"world"}`, 100)
+
+	for _, encoding := range []string{"br", "zstd", "gzip"} {
+		t.Run(encoding, func(t *testing.T) {
+			h := handler.NewAPIGatewayV2Handler(func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+				w.Header().Set("ETag", `"abc"`)
+				_, _ = w.Write([]byte(body))
+				return nil
+			}, handler.WithCompression(1024))
+
+			res, err := h(context.Background(), handlertest.APIGatewayV2().Header("Accept-Encoding", encoding).Build())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if res.Headers["Content-Encoding"] != encoding || res.Headers["Vary"] != "Accept-Encoding" || res.Headers["Etag"] != `W/"abc"` {
+				t.Fatalf("unexpected headers: %v", res.Headers)
+			}
+
+			b, _ := base64.StdEncoding.DecodeString(res.Body)
+			if res.Headers["Content-Length"] != strconv.Itoa(len(b)) {
+				t.Errorf("expected Content-Length %d, got %s", len(b), res.Headers["Content-Length"])
+			}
+
+			if actual := decompress(t, encoding, b); actual != body {
+				t.Errorf("unexpected body: %q", actual)
+			}
+		})
+	}
+}
+
+func TestCompressionSkipped(t *testing.T) {
+	tests := map[string]struct {
+		contentType     string
+		contentEncoding string
+		body            string
+		vary            string
+	}{
+		"small":           {"text/plain", "", "hello world", "Accept-Encoding"},
+		"content type":    {"image/png", "", strings.Repeat("a", 2048), ""},
+		"already encoded": {"text/plain", "gzip", strings.Repeat("a", 2048), ""},
+		"not accepted":    {"text/plain", "", strings.Repeat("a", 2048), "Accept-Encoding"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			h := handler.NewAPIGatewayV2Handler(func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+				w.Header().Set("Content-Type", tt.contentType)
+				if tt.contentEncoding != "" {
+					w.Header().Set("Content-Encoding", tt.contentEncoding)
+				}
+
+				_, _ = w.Write([]byte(tt.body))
+				return nil
+			}, handler.WithCompression(1024))
+
+			acceptEncoding := "gzip"
+			if name == "not accepted" {
+				acceptEncoding = "identity"
+			}
+
+			res, err := h(context.Background(), handlertest.APIGatewayV2().Header("Accept-Encoding", acceptEncoding).Build())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if res.Body != tt.body || res.Headers["Content-Encoding"] != tt.contentEncoding || res.Headers["Vary"] != tt.vary {
+				t.Errorf("expected the response not to be compressed, got %v", res.Headers)
+			}
+		})
+	}
+}
+
+func TestCompressionRange(t *testing.T) {
+	body := strings.Repeat("a", 2048)
+
+	tests := map[string]int{
+		"partial content": http.StatusPartialContent,
+		"content range":   http.StatusRequestedRangeNotSatisfiable,
+	}
+
+	for name, statusCode := range tests {
+		t.Run(name, func(t *testing.T) {
+			h := handler.NewAPIGatewayV2Handler(func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+				w.Header().Set("Content-Type", "text/plain")
+				w.Header().Set("Content-Range", "bytes 0-2047/4096")
+				w.WriteHeader(statusCode)
+				_, _ = w.Write([]byte(body))
+				return nil
+			}, handler.WithCompression(1024))
+
+			res, err := h(context.Background(), handlertest.APIGatewayV2().Header("Accept-Encoding", "gzip").Build())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if res.Body != body || res.Headers["Content-Encoding"] != "" {
+				t.Errorf("expected the response not to be compressed, got %v", res.Headers)
+			}
+		})
+	}
+}
+
+func TestCompressionLargeResponseStore(t *testing.T) {
+	var sb strings.Builder
+	for i := 0; i < 2000; i++ {
+		sb.WriteString(strconv.Itoa(i * 7919))
+		sb.WriteByte(',')
+	}
+
+	body := sb.String()
+	store := &handlertest.MemoryStore{}
+
+	h := handler.NewAPIGatewayV2Handler(func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+		w.Header().Set("Content-Type", "text/csv")
+		_, _ = w.Write([]byte(body))
+		return nil
+	}, handler.WithCompression(1024), handler.WithMaxResponseSize(2048), handler.WithLargeResponseStore(store, http.StatusSeeOther))
+
+	res, err := h(context.Background(), handlertest.APIGatewayV2().Header("Accept-Encoding", "gzip").Build())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.StatusCode != http.StatusSeeOther || res.Headers["Content-Encoding"] != "" {
+		t.Fatalf("expected an uncompressed redirect, got %d %v", res.StatusCode, res.Headers)
+	}
+
+	stored, ok := store.Get(res.Headers["Location"])
+	if !ok {
+		t.Fatalf("expected the body to be stored at %q", res.Headers["Location"])
+	}
+
+	if stored.Header.Get("Content-Encoding") != "" || bytes.HasPrefix(stored.Body, []byte{0x1f, 0x8b}) || string(stored.Body) != body {
+		t.Errorf("expected the uncompressed body to be stored, got %v", stored.Header)
+	}
+}
+
+func TestCompressionFitsMaxResponseSize(t *testing.T) {
+	body := strings.Repeat("a", 4096)
+
+	h := handler.NewAPIGatewayV2Handler(func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte(body))
+		return nil
+	}, handler.WithCompression(1024), handler.WithMaxResponseSize(2048), handler.WithLargeResponseStore(&handlertest.MemoryStore{}, http.StatusSeeOther))
+
+	res, err := h(context.Background(), handlertest.APIGatewayV2().Header("Accept-Encoding", "gzip").Build())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.StatusCode != http.StatusOK || res.Headers["Content-Encoding"] != "gzip" {
+		t.Fatalf("expected the compressed response to be sent inline, got %d %v", res.StatusCode, res.Headers)
+	}
+
+	b, _ := base64.StdEncoding.DecodeString(res.Body)
+	if actual := decompress(t, "gzip", b); actual != body {
+		t.Errorf("unexpected body: %q", actual)
+	}
+}
+
+func TestCompressionStreaming(t *testing.T) {
+	next := make(chan struct{})
+	h := handler.NewFunctionURLStreamingHandler(func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("chunk 1\n"))
+
+		<-next
+		_, _ = w.Write([]byte("chunk 2\n"))
+		return nil
+	}, handler.WithCompression(1024))
+
+	res, err := h(context.Background(), handlertest.FunctionURL().Header("Accept-Encoding", "gzip").Build())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Headers["Content-Encoding"] != "gzip" {
+		t.Fatalf("unexpected headers: %v", res.Headers)
+	}
+
+	zr, err := gzip.NewReader(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := bufio.NewReader(zr)
+	if line, err := r.ReadString('\n'); err != nil || line != "chunk 1\n" {
+		t.Fatalf("expected the first chunk to arrive before the stream ended, got %q (%v)", line, err)
+	}
+
+	close(next)
+
+	if rest, err := io.ReadAll(r); err != nil || string(rest) != "chunk 2\n" {
+		t.Errorf("unexpected remaining body: %q (%v)", rest, err)
+	}
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/flate"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zlib"
+	"github.com/klauspost/compress/zstd"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ErrRequestBodyTooLarge is matched by the error passed to the ErrorResponder, in addition to ErrRequestConversion, if a decompressed request body exceeds the maximum size
+var ErrRequestBodyTooLarge = errors.New("the decompressed request body exceeds the maximum size")
+
+// WithRequestDecompression decompresses request bodies with a Content-Encoding of gzip, deflate, br or zstd before the request is passed to the adapter.
+// maxSize limits the size of the decompressed body to guard against decompression bombs, larger bodies fail the conversion of the event with ErrRequestBodyTooLarge.
+// Content-Encoding is removed and Content-Length is set to the decompressed size. Bodies with other encodings are passed as is.
+func WithRequestDecompression(maxSize int64) Option {
+	return func(o *options) {
+		o.maxDecompressedRequestSize = maxSize
+	}
+}
+
+// decompressRequest replaces the body of the request with the decompressed body according to WithRequestDecompression
+func (o *options) decompressRequest(req *http.Request) error {
+	if o.maxDecompressedRequestSize <= 0 || req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+
+	encodings := make([]string, 0)
+	for _, v := range req.Header.Values("Content-Encoding") {
+		for _, part := range strings.Split(v, ",") {
+			if encoding := strings.ToLower(strings.TrimSpace(part)); encoding != "" && encoding != "identity" {
+				switch encoding {
+				case "gzip", "x-gzip", "deflate", "br", "zstd":
+					encodings = append(encodings, encoding)
+				default:
+					return nil
+				}
+			}
+		}
+	}
+
+	if len(encodings) == 0 {
+		return nil
+	}
+
+	var r io.Reader = req.Body
+
+	// encodings are listed in the order they were applied
+	for i := len(encodings) - 1; i >= 0; i-- {
+		var err error
+		if r, err = newDecoder(encodings[i], r); err != nil {
+			return fmt.Errorf("invalid %s request body: %w", encodings[i], err)
+		}
+	}
+
+	b, err := io.ReadAll(io.LimitReader(r, o.maxDecompressedRequestSize+1))
+	if closer, ok := r.(io.Closer); ok {
+		_ = closer.Close()
+	}
+
+	if err != nil {
+		return fmt.Errorf("invalid request body: %w", err)
+	} else if int64(len(b)) > o.maxDecompressedRequestSize {
+		return fmt.Errorf("%w: more than %d bytes", ErrRequestBodyTooLarge, o.maxDecompressedRequestSize)
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(b))
+	req.ContentLength = int64(len(b))
+	req.Header.Del("Content-Encoding")
+	req.Header.Set("Content-Length", strconv.Itoa(len(b)))
+
+	return nil
+}
+
+// zstdDecoder closes the decoder once the body was read
+type zstdDecoder struct {
+	*zstd.Decoder
+}
+
+func (d zstdDecoder) Close() error {
+	d.Decoder.Close()
+	return nil
+}
+
+func newDecoder(encoding string, r io.Reader) (io.Reader, error) {
+	switch encoding {
+	case "gzip", "x-gzip":
+		return gzip.NewReader(r)
+	case "br":
+		return brotli.NewReader(r), nil
+	case "zstd":
+		d, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+
+		return zstdDecoder{d}, nil
+	default:
+		// deflate is the zlib format, but some clients send raw deflate data
+		br := bufio.NewReader(r)
+		if header, err := br.Peek(2); err == nil && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 && header[0]&0x0f == 8 {
+			return zlib.NewReader(br)
+		}
+
+		return flate.NewReader(br), nil
+	}
+}
@@ -0,0 +1,167 @@
+//go:build !lambdahttpadapter.partial || (lambdahttpadapter.partial && lambdahttpadapter.apigwv2)
+
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/andybalholm/brotli"
+	"github.com/its-felix/aws-lambda-go-http-adapter/handler"
+	"github.com/its-felix/aws-lambda-go-http-adapter/handlertest"
+	"github.com/klauspost/compress/flate"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zlib"
+	"github.com/klauspost/compress/zstd"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func compressBody(t *testing.T, encoding string, body []byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	var err error
+
+	switch encoding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "deflate":
+		w = zlib.NewWriter(&buf)
+	case "raw deflate":
+		w, err = flate.NewWriter(&buf, flate.DefaultCompression)
+	case "br":
+		w = brotli.NewWriter(&buf)
+	case "zstd":
+		w, err = zstd.NewWriter(&buf)
+	default:
+		t.Fatalf("unexpected encoding %q", encoding)
+	}
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _ = w.Write(body)
+	_ = w.Close()
+
+	return buf.Bytes()
+}
+
+func echoBody(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("X-Content-Encoding", r.Header.Get("Content-Encoding"))
+	w.Header().Set("X-Content-Length", r.Header.Get("Content-Length"))
+	_, _ = w.Write(b)
+
+	return nil
+}
+
+func TestRequestDecompression(t *testing.T) {
+	body := strings.Repeat("hello world\n", 100)
+
+	for _, encoding := range []string{"gzip", "deflate", "raw deflate", "br", "zstd"} {
+		t.Run(encoding, func(t *testing.T) {
+			h := handler.NewAPIGatewayV2Handler(echoBody, handler.WithRequestDecompression(4096))
+
+			contentEncoding := strings.TrimPrefix(encoding, "raw ")
+			res, err := h(context.Background(), handlertest.APIGatewayV2().POST("/").Header("Content-Encoding", contentEncoding).Body(compressBody(t, encoding, []byte(body))).Build())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if res.Body != body {
+				t.Errorf("unexpected body: %q", res.Body)
+			}
+
+			if res.Headers["X-Content-Encoding"] != "" || res.Headers["X-Content-Length"] != "1200" {
+				t.Errorf("unexpected request headers: %v", res.Headers)
+			}
+		})
+	}
+}
+
+func TestRequestDecompressionMultiple(t *testing.T) {
+	body := compressBody(t, "br", compressBody(t, "gzip", []byte("hello world")))
+	h := handler.NewAPIGatewayV2Handler(echoBody, handler.WithRequestDecompression(4096))
+
+	res, err := h(context.Background(), handlertest.APIGatewayV2().POST("/").Header("Content-Encoding", "gzip, br").Body(body).Build())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Body != "hello world" {
+		t.Errorf("unexpected body: %q", res.Body)
+	}
+}
+
+func TestRequestDecompressionSkipped(t *testing.T) {
+	body := compressBody(t, "gzip", []byte("hello world"))
+
+	tests := map[string]struct {
+		contentEncoding string
+		opts            []handler.Option
+	}{
+		"disabled":            {"gzip", nil},
+		"unsupported":         {"compress", []handler.Option{handler.WithRequestDecompression(4096)}},
+		"partially supported": {"gzip, compress", []handler.Option{handler.WithRequestDecompression(4096)}},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			h := handler.NewAPIGatewayV2Handler(echoBody, tt.opts...)
+
+			res, err := h(context.Background(), handlertest.APIGatewayV2().POST("/").Header("Content-Encoding", tt.contentEncoding).Body(body).Build())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if res.Headers["X-Content-Encoding"] != tt.contentEncoding {
+				t.Errorf("expected the request not to be decompressed, got %v", res.Headers)
+			}
+		})
+	}
+}
+
+func TestRequestDecompressionTooLarge(t *testing.T) {
+	body := compressBody(t, "zstd", bytes.Repeat([]byte{0}, 1<<20))
+	h := handler.NewAPIGatewayV2Handler(func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+		t.Error("expected the adapter not to be called")
+		return nil
+	}, handler.WithRequestDecompression(1024), handler.WithErrorResponder(handler.ProblemDetailsErrorResponder))
+
+	res, err := h(context.Background(), handlertest.APIGatewayV2().POST("/").Header("Content-Encoding", "zstd").Body(body).Build())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected status 413, got %d", res.StatusCode)
+	}
+}
+
+func TestRequestDecompressionInvalid(t *testing.T) {
+	h := handler.NewAPIGatewayV2Handler(echoBody, handler.WithRequestDecompression(1024), handler.WithErrorResponder(func(ctx context.Context, event any, err error) (int, http.Header, []byte) {
+		if !errors.Is(err, handler.ErrRequestConversion) || errors.Is(err, handler.ErrRequestBodyTooLarge) {
+			t.Errorf("expected a conversion error, got %v", err)
+		}
+
+		return http.StatusBadRequest, nil, nil
+	}))
+
+	res, err := h(context.Background(), handlertest.APIGatewayV2().POST("/").Header("Content-Encoding", "gzip").Body([]byte("hello world")).Build())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", res.StatusCode)
+	}
+}
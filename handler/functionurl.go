@@ -5,16 +5,22 @@ package handler
 import (
 	"bytes"
 	"context"
-	"encoding/base64"
+	"fmt"
 	"github.com/aws/aws-lambda-go/events"
 	"io"
 	"net/http"
+	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
-	"unicode/utf8"
+	"time"
 )
 
+func init() {
+	registerAutoHandler(eventFormatFunctionURL, handleFunctionURL, newFunctionURLResponse)
+}
+
 func convertFunctionURLRequest(ctx context.Context, event events.LambdaFunctionURLRequest) (*http.Request, error) {
 	url := buildFullRequestURL(event.RequestContext.DomainName, event.RawPath, event.RequestContext.HTTP.Path, buildQuery(event.RawQueryString, event.QueryStringParameters))
 	req, err := http.NewRequestWithContext(ctx, event.RequestContext.HTTP.Method, url, getBody(event.Body, event.IsBase64Encoded))
@@ -38,114 +44,97 @@ func convertFunctionURLRequest(ctx context.Context, event events.LambdaFunctionU
 		req.ProtoMajor, req.ProtoMinor = pMajor, pMinor
 	}
 
-	req.RemoteAddr = event.RequestContext.HTTP.SourceIP + ":http"
+	setHost(req, event.RequestContext.DomainName)
+	req.TLS = newTLSConnectionState(req, "")
+	req = withSourceIP(req, event.RequestContext.HTTP.SourceIP)
 	req.RequestURI = req.URL.RequestURI()
 
 	return req, nil
 }
 
 // region classic
-type functionURLResponseWriter struct {
-	headersWritten   bool
-	contentTypeSet   bool
-	contentLengthSet bool
-	headers          http.Header
-	body             bytes.Buffer
-	res              events.LambdaFunctionURLResponse
-}
-
-func (w *functionURLResponseWriter) Header() http.Header {
-	return w.headers
-}
-
-func (w *functionURLResponseWriter) Write(p []byte) (int, error) {
-	w.WriteHeader(http.StatusOK)
-	return w.body.Write(p)
-}
-
-func (w *functionURLResponseWriter) WriteHeader(statusCode int) {
-	if !w.headersWritten {
-		w.headersWritten = true
-		w.res.StatusCode = statusCode
+func newFunctionURLResponse(statusCode int, headers http.Header, body []byte, o *options) events.LambdaFunctionURLResponse {
+	res := events.LambdaFunctionURLResponse{
+		StatusCode: statusCode,
+		Headers:    make(map[string]string),
+		Cookies:    make([]string, 0),
+	}
 
-		for k, values := range w.headers {
-			if strings.EqualFold("set-cookie", k) {
-				w.res.Cookies = values
+	for k, values := range headers {
+		if strings.EqualFold("set-cookie", k) {
+			res.Cookies = values
+		} else {
+			if len(values) == 0 {
+				res.Headers[k] = ""
+			} else if len(values) == 1 {
+				res.Headers[k] = values[0]
 			} else {
-				if len(values) == 0 {
-					w.res.Headers[k] = ""
-				} else if len(values) == 1 {
-					w.res.Headers[k] = values[0]
-				} else {
-					w.res.Headers[k] = strings.Join(values, ",")
-				}
-			}
-
-			if strings.EqualFold("content-type", k) {
-				w.contentTypeSet = true
-			} else if strings.EqualFold("content-length", k) {
-				w.contentLengthSet = true
+				res.Headers[k] = o.foldHeader(k, values)
 			}
 		}
 	}
+
+	res.Body, res.IsBase64Encoded = o.encodeBody(headers, body)
+
+	return res
 }
 
-func handleFunctionURL(ctx context.Context, event events.LambdaFunctionURLRequest, adapter AdapterFunc) (events.LambdaFunctionURLResponse, error) {
+func handleFunctionURL(ctx context.Context, event events.LambdaFunctionURLRequest, adapter AdapterFunc, o *options) (events.LambdaFunctionURLResponse, error) {
 	req, err := convertFunctionURLRequest(ctx, event)
 	if err != nil {
-		var def events.LambdaFunctionURLResponse
-		return def, err
+		return respondError(ctx, o, newFunctionURLResponse, ErrRequestConversion, err)
 	}
 
-	w := functionURLResponseWriter{
-		headers: make(http.Header),
-		res: events.LambdaFunctionURLResponse{
-			Headers: make(map[string]string),
-			Cookies: make([]string, 0),
-		},
-	}
+	o.resolveClientIP(req)
 
-	if err = adapter(ctx, req, &w); err != nil {
-		var def events.LambdaFunctionURLResponse
-		return def, err
+	if err = o.decompressRequest(req); err != nil {
+		return respondError(ctx, o, newFunctionURLResponse, ErrRequestConversion, err)
 	}
 
-	b, err := io.ReadAll(&w.body)
-	if err != nil {
-		var def events.LambdaFunctionURLResponse
-		return def, err
-	}
+	ctx = req.Context()
 
-	if !w.contentTypeSet {
-		w.res.Headers["Content-Type"] = http.DetectContentType(b)
-	}
+	w := newBufferedResponseWriter()
 
-	if !w.contentLengthSet {
-		w.res.Headers["Content-Length"] = strconv.Itoa(len(b))
+	if err = o.runAdapter(ctx, adapter, req, w); err != nil {
+		return respondError(ctx, o, newFunctionURLResponse, ErrAdapter, err)
 	}
 
-	if utf8.Valid(b) {
-		w.res.Body = string(b)
-	} else {
-		w.res.IsBase64Encoded = true
-		w.res.Body = base64.StdEncoding.EncodeToString(b)
+	statusCode, headers, b, err := w.result(ctx, o)
+	if err != nil {
+		return respondError(ctx, o, newFunctionURLResponse, ErrResponseBody, err)
 	}
 
-	return w.res, nil
+	return finalizeResponse(ctx, o, newFunctionURLResponse, w.encoding, statusCode, headers, b)
 }
 
-func NewFunctionURLHandler(adapter AdapterFunc) func(context.Context, events.LambdaFunctionURLRequest) (events.LambdaFunctionURLResponse, error) {
-	return NewHandler(handleFunctionURL, adapter)
+func NewFunctionURLHandler(adapter AdapterFunc, opts ...Option) func(context.Context, events.LambdaFunctionURLRequest) (events.LambdaFunctionURLResponse, error) {
+	return NewHandler(withOptions(handleFunctionURL, newOptions(opts)), adapter)
 }
 
 // endregion
 
 // region streaming
+// states of functionURLStreamingResponseWriter.headersWritten
+const (
+	headersPending int32 = iota
+	headersSent
+	headersTimedOut
+)
+
 type functionURLStreamingResponseWriter struct {
-	headers        http.Header
-	headersWritten int32
-	body           io.WriteCloser
-	resCh          chan<- events.LambdaFunctionURLStreamingResponse
+	headers          http.Header
+	headersWritten   int32
+	body             *io.PipeWriter
+	encoding         string
+	enc              encoder
+	o                *options
+	ctx              context.Context
+	timeoutCtx       context.Context
+	resCh            chan<- events.LambdaFunctionURLStreamingResponse
+	deadline         time.Time
+	deadlineMu       sync.Mutex
+	deadlineTimer    *time.Timer
+	deadlineExceeded int32
 }
 
 func (w *functionURLStreamingResponseWriter) Header() http.Header {
@@ -153,42 +142,156 @@ func (w *functionURLStreamingResponseWriter) Header() http.Header {
 }
 
 func (w *functionURLStreamingResponseWriter) Write(p []byte) (int, error) {
+	if atomic.LoadInt32(&w.deadlineExceeded) == 1 {
+		return 0, os.ErrDeadlineExceeded
+	} else if atomic.LoadInt32(&w.headersWritten) == headersTimedOut || timeoutReached(w.timeoutCtx) {
+		return 0, http.ErrHandlerTimeout
+	}
+
 	w.WriteHeader(http.StatusOK)
+
+	// the timeout might have sent its response in between, in which case there is no body to write to
+	if atomic.LoadInt32(&w.headersWritten) == headersTimedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+
+	// every chunk is flushed, so that it's passed downstream immediately like without compression
+	if w.enc != nil {
+		n, err := w.enc.Write(p)
+		if err == nil {
+			err = w.enc.Flush()
+		}
+
+		return n, err
+	}
+
 	return w.body.Write(p)
 }
 
 func (w *functionURLStreamingResponseWriter) WriteHeader(statusCode int) {
-	if atomic.CompareAndSwapInt32(&w.headersWritten, 0, 1) {
-		pr, pw := io.Pipe()
-		w.body = pw
+	// the body is set while holding the lock, so that timeout always sees it once the headers were sent
+	w.deadlineMu.Lock()
+	if !atomic.CompareAndSwapInt32(&w.headersWritten, headersPending, headersSent) {
+		w.deadlineMu.Unlock()
+		return
+	}
 
-		headers := make(map[string]string)
-		cookies := make([]string, 0)
+	pr, pw := io.Pipe()
+	w.body = pw
+	w.deadlineMu.Unlock()
 
-		for k, values := range w.headers {
-			if strings.EqualFold("set-cookie", k) {
-				cookies = values
-			} else {
-				if len(values) == 0 {
-					headers[k] = ""
-				} else if len(values) == 1 {
-					headers[k] = values[0]
-				} else {
-					headers[k] = strings.Join(values, ",")
-				}
-			}
-		}
+	size := -1
+	if cl := w.headers.Get("Content-Length"); cl != "" {
+		size, _ = strconv.Atoi(cl)
+	}
 
-		w.resCh <- events.LambdaFunctionURLStreamingResponse{
-			StatusCode: statusCode,
-			Headers:    headers,
-			Body:       pr,
-			Cookies:    cookies,
+	if w.o.shouldCompress(w.encoding, statusCode, w.headers, size) {
+		if enc, err := newEncoder(w.encoding, pw); err == nil {
+			w.enc = enc
+			setContentEncoding(w.headers, w.encoding)
+			w.headers.Del("Content-Length")
 		}
 	}
+
+	w.o.setRequestIDHeaders(w.ctx, w.headers)
+	w.resCh <- newFunctionURLStreamingResponse(statusCode, w.headers, pr, w.o)
+}
+
+// timeout sends the timeout response if the headers weren't sent yet, otherwise the body is closed with ErrTimeout
+func (w *functionURLStreamingResponseWriter) timeout() {
+	w.deadlineMu.Lock()
+	if atomic.CompareAndSwapInt32(&w.headersWritten, headersPending, headersTimedOut) {
+		w.deadlineMu.Unlock()
+
+		res, _ := respondError(w.ctx, w.o, newFunctionURLStreamingErrorResponse, ErrAdapter, newTimeoutError())
+		w.resCh <- *res
+		return
+	}
+
+	defer w.deadlineMu.Unlock()
+
+	if w.body != nil {
+		_ = w.body.CloseWithError(ErrTimeout)
+	}
+}
+
+// Flush sends the headers downstream if that didn't happen yet.
+// Everything written to the body is passed downstream immediately, so there is nothing else to flush.
+func (w *functionURLStreamingResponseWriter) Flush() {
+	w.WriteHeader(http.StatusOK)
+}
+
+// Unwrap allows http.ResponseController to traverse the writer.
+// The writer doesn't wrap another http.ResponseWriter, so nil is returned.
+func (w *functionURLStreamingResponseWriter) Unwrap() http.ResponseWriter {
+	return nil
+}
+
+// SetWriteDeadline sets the deadline for writes to the body, it can't be later than the deadline of the Lambda invocation.
+// A zero value resets the deadline to the deadline of the Lambda invocation.
+// Once the deadline is exceeded, all writes fail with os.ErrDeadlineExceeded and the body is closed with the same error.
+func (w *functionURLStreamingResponseWriter) SetWriteDeadline(deadline time.Time) error {
+	if deadline.IsZero() || (!w.deadline.IsZero() && deadline.After(w.deadline)) {
+		deadline = w.deadline
+	}
+
+	w.deadlineMu.Lock()
+	defer w.deadlineMu.Unlock()
+
+	if w.deadlineTimer != nil {
+		w.deadlineTimer.Stop()
+		w.deadlineTimer = nil
+	}
+
+	atomic.StoreInt32(&w.deadlineExceeded, 0)
+
+	if !deadline.IsZero() {
+		w.deadlineTimer = time.AfterFunc(time.Until(deadline), w.onDeadlineExceeded)
+	}
+
+	return nil
+}
+
+func (w *functionURLStreamingResponseWriter) onDeadlineExceeded() {
+	w.deadlineMu.Lock()
+	defer w.deadlineMu.Unlock()
+
+	atomic.StoreInt32(&w.deadlineExceeded, 1)
+
+	if w.body != nil {
+		_ = w.body.CloseWithError(os.ErrDeadlineExceeded)
+	}
+}
+
+// closeWithError closes the body with the given error, it returns false if the headers weren't sent yet.
+// Once the timeout response was sent, the error is discarded.
+func (w *functionURLStreamingResponseWriter) closeWithError(err error) bool {
+	w.deadlineMu.Lock()
+	defer w.deadlineMu.Unlock()
+
+	if atomic.LoadInt32(&w.headersWritten) == headersTimedOut {
+		return true
+	} else if w.body == nil {
+		return false
+	}
+
+	_ = w.body.CloseWithError(err)
+	return true
 }
 
 func (w *functionURLStreamingResponseWriter) Close() error {
+	// writes the end of the compressed body, this blocks until it was read and therefore must not hold the lock
+	if w.enc != nil {
+		_ = w.enc.Close()
+	}
+
+	w.deadlineMu.Lock()
+	defer w.deadlineMu.Unlock()
+
+	if w.deadlineTimer != nil {
+		w.deadlineTimer.Stop()
+	}
+
 	if w.body == nil {
 		return nil
 	}
@@ -196,23 +299,62 @@ func (w *functionURLStreamingResponseWriter) Close() error {
 	return w.body.Close()
 }
 
-func handleFunctionURLStreaming(ctx context.Context, event events.LambdaFunctionURLRequest, adapter AdapterFunc) (*events.LambdaFunctionURLStreamingResponse, error) {
+func newFunctionURLStreamingResponse(statusCode int, headers http.Header, body io.Reader, o *options) events.LambdaFunctionURLStreamingResponse {
+	res := events.LambdaFunctionURLStreamingResponse{
+		StatusCode: statusCode,
+		Headers:    make(map[string]string),
+		Body:       body,
+		Cookies:    make([]string, 0),
+	}
+
+	for k, values := range headers {
+		if strings.EqualFold("set-cookie", k) {
+			res.Cookies = values
+		} else {
+			if len(values) == 0 {
+				res.Headers[k] = ""
+			} else if len(values) == 1 {
+				res.Headers[k] = values[0]
+			} else {
+				res.Headers[k] = o.foldHeader(k, values)
+			}
+		}
+	}
+
+	return res
+}
+
+func newFunctionURLStreamingErrorResponse(statusCode int, headers http.Header, body []byte, o *options) *events.LambdaFunctionURLStreamingResponse {
+	res := newFunctionURLStreamingResponse(statusCode, headers, bytes.NewReader(body), o)
+	return &res
+}
+
+func handleFunctionURLStreaming(ctx context.Context, event events.LambdaFunctionURLRequest, adapter AdapterFunc, o *options) (*events.LambdaFunctionURLStreamingResponse, error) {
 	req, err := convertFunctionURLRequest(ctx, event)
 	if err != nil {
-		return nil, err
+		return respondError(ctx, o, newFunctionURLStreamingErrorResponse, ErrRequestConversion, err)
 	}
 
-	resCh := make(chan events.LambdaFunctionURLStreamingResponse)
-	errCh := make(chan error)
-	panicCh := make(chan any)
+	o.resolveClientIP(req)
 
-	go processRequestFunctionURLStreaming(ctx, req, adapter, resCh, errCh, panicCh)
+	if err = o.decompressRequest(req); err != nil {
+		return respondError(ctx, o, newFunctionURLStreamingErrorResponse, ErrRequestConversion, err)
+	}
+
+	ctx = req.Context()
+
+	// buffered, so that the goroutine never blocks once this function returned
+	resCh := make(chan events.LambdaFunctionURLStreamingResponse, 1)
+	errCh := make(chan error, 1)
+	panicCh := make(chan any, 1)
+
+	go processRequestFunctionURLStreaming(ctx, req, adapter, o, resCh, errCh, panicCh)
 
 	select {
 	case res := <-resCh:
 		return &res, nil
 	case err = <-errCh:
-		return nil, err
+		return respondError(ctx, o, newFunctionURLStreamingErrorResponse, ErrAdapter, err)
 	case panicV := <-panicCh:
 		panic(panicV)
 	case <-ctx.Done():
@@ -220,34 +362,68 @@ func handleFunctionURLStreaming(ctx context.Context, event events.LambdaFunction
 	}
 }
 
-func processRequestFunctionURLStreaming(ctx context.Context, req *http.Request, adapter AdapterFunc, resCh chan<- events.LambdaFunctionURLStreamingResponse, errCh chan<- error, panicCh chan<- any) {
-	ctx, cancel := context.WithCancel(ctx)
-	defer func() {
-		if panicV := recover(); panicV != nil {
-			panicCh <- panicV
-		}
-
-		close(panicCh)
-		close(resCh)
-		close(errCh)
-		cancel()
-	}()
+func processRequestFunctionURLStreaming(ctx context.Context, req *http.Request, adapter AdapterFunc, o *options, resCh chan<- events.LambdaFunctionURLStreamingResponse, errCh chan<- error, panicCh chan<- any) {
+	ctx, cancel, timeout := o.timeoutContext(ctx)
+	defer cancel()
 
 	w := functionURLStreamingResponseWriter{
 		headers:        make(http.Header),
-		headersWritten: 0,
+		headersWritten: headersPending,
+		encoding:       o.negotiateEncoding(req),
+		o:              o,
+		ctx:            ctx,
 		resCh:          resCh,
 	}
 
+	if timeout {
+		req = req.WithContext(ctx)
+		w.timeoutCtx = ctx
+
+		go func() {
+			<-ctx.Done()
+
+			// canceled once the adapter returned
+			if ctx.Err() == context.DeadlineExceeded {
+				w.timeout()
+			}
+		}()
+	}
+
+	w.deadline, _ = ctx.Deadline()
+
 	defer w.Close()
 
-	if err := adapter(ctx, req, &w); err != nil {
-		errCh <- err
+	// once the headers were sent, errors can only be reported by closing the body
+	defer func() {
+		if panicV := recover(); panicV != nil {
+			if !w.closeWithError(fmt.Errorf("panic: %v", panicV)) {
+				panicCh <- panicV
+			}
+		}
+	}()
+
+	err := adapter(ctx, req, &w)
+
+	// writes already failed if the timeout was reached before the adapter returned
+	if timeoutReached(w.timeoutCtx) {
+		w.timeout()
+		return
 	}
+
+	if err != nil {
+		if !w.closeWithError(err) {
+			errCh <- err
+		}
+
+		return
+	}
+
+	// an empty response is still a response
+	w.WriteHeader(http.StatusOK)
 }
 
-func NewFunctionURLStreamingHandler(adapter AdapterFunc) func(context.Context, events.LambdaFunctionURLRequest) (*events.LambdaFunctionURLStreamingResponse, error) {
-	return NewHandler(handleFunctionURLStreaming, adapter)
+func NewFunctionURLStreamingHandler(adapter AdapterFunc, opts ...Option) func(context.Context, events.LambdaFunctionURLRequest) (*events.LambdaFunctionURLStreamingResponse, error) {
+	return NewHandler(withOptions(handleFunctionURLStreaming, newOptions(opts)), adapter)
 }
 
 // endregion
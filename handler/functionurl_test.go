@@ -0,0 +1,90 @@
+//go:build !lambdahttpadapter.partial || (lambdahttpadapter.partial && lambdahttpadapter.functionurl)
+
+package handler
+
+import (
+	"context"
+	"errors"
+	"github.com/aws/aws-lambda-go/events"
+	"io"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestFunctionURLStreamingWriteDeadline(t *testing.T) {
+	writeErrCh := make(chan error, 1)
+
+	h := NewFunctionURLStreamingHandler(func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+		rc := http.NewResponseController(w)
+		if err := rc.SetWriteDeadline(time.Now().Add(50 * time.Millisecond)); err != nil {
+			return err
+		}
+
+		if err := rc.Flush(); err != nil {
+			return err
+		}
+
+		time.Sleep(100 * time.Millisecond)
+
+		_, err := w.Write([]byte("too late"))
+		writeErrCh <- err
+
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	res, err := h(ctx, events.LambdaFunctionURLRequest{
+		RequestContext: events.LambdaFunctionURLRequestContext{
+			HTTP: events.LambdaFunctionURLRequestContextHTTPDescription{
+				Method: http.MethodGet,
+			},
+		},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = io.ReadAll(res.Body); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("expected the body to be closed with os.ErrDeadlineExceeded, got %v", err)
+	}
+
+	if err = <-writeErrCh; !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("expected the write to fail with os.ErrDeadlineExceeded, got %v", err)
+	}
+}
+
+// timeoutOnErrContext runs the timeout of the writer once Write checked whether the timeout was reached
+type timeoutOnErrContext struct {
+	context.Context
+	w *functionURLStreamingResponseWriter
+}
+
+func (ctx timeoutOnErrContext) Err() error {
+	ctx.w.timeout()
+	return nil
+}
+
+func TestFunctionURLStreamingWriteTimeoutBeforeHeaders(t *testing.T) {
+	resCh := make(chan events.LambdaFunctionURLStreamingResponse, 1)
+	w := &functionURLStreamingResponseWriter{
+		headers:        make(http.Header),
+		headersWritten: headersPending,
+		o:              newOptions([]Option{WithTimeout(0, nil)}),
+		ctx:            context.Background(),
+		resCh:          resCh,
+	}
+	w.timeoutCtx = timeoutOnErrContext{context.Background(), w}
+
+	if _, err := w.Write([]byte("too late")); !errors.Is(err, http.ErrHandlerTimeout) {
+		t.Errorf("expected the write to fail with http.ErrHandlerTimeout, got %v", err)
+	}
+
+	if res := <-resCh; res.StatusCode != http.StatusGatewayTimeout {
+		t.Errorf("expected the timeout response, got status %d", res.StatusCode)
+	}
+}
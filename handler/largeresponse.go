@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LargeResponseStore stores the bodies of responses exceeding the maximum response size
+type LargeResponseStore interface {
+	// Put stores the body and returns the URL the client is redirected to.
+	// headers are the headers of the original response, for example to keep Content-Type or Content-Disposition.
+	Put(ctx context.Context, headers http.Header, body []byte) (string, error)
+}
+
+// WithLargeResponseStore stores the body of successful (2xx) buffered responses exceeding the maximum size using the given store
+// and redirects the client to the returned URL instead. statusCode is either http.StatusFound or http.StatusSeeOther (the default if 0).
+// Other responses exceeding the maximum size fail like without a store.
+// The store replaces any hook set using WithOversizedResponseHook.
+func WithLargeResponseStore(store LargeResponseStore, statusCode int) Option {
+	if statusCode == 0 {
+		statusCode = http.StatusSeeOther
+	}
+
+	return WithOversizedResponseHook(func(ctx context.Context, sc int, headers http.Header, body []byte) (int, http.Header, []byte, error) {
+		if sc < 200 || sc > 299 {
+			return sc, headers, body, nil
+		}
+
+		location, err := store.Put(ctx, headers, body)
+		if err != nil {
+			return 0, nil, nil, err
+		}
+
+		redirect := make(http.Header)
+		redirect.Set("Location", location)
+		redirect.Set("Cache-Control", "no-store")
+		redirect.Set("Content-Length", "0")
+
+		// cookies are still set, the client doesn't send them to the store
+		if values := headers.Values("Set-Cookie"); len(values) > 0 {
+			redirect["Set-Cookie"] = values
+		}
+
+		return statusCode, redirect, nil, nil
+	})
+}
+
+// FileStore is a LargeResponseStore which writes the bodies to Dir, for example a mounted EFS file system served by a web server.
+// The URL is BaseURL followed by the generated file name, the extension is derived from the Content-Type.
+type FileStore struct {
+	Dir     string
+	BaseURL string
+}
+
+func (s FileStore) Put(ctx context.Context, headers http.Header, body []byte) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	name := hex.EncodeToString(b)
+	if exts, _ := mime.ExtensionsByType(headers.Get("Content-Type")); len(exts) > 0 {
+		name += exts[0]
+	}
+
+	if err := os.WriteFile(filepath.Join(s.Dir, name), body, 0o644); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(s.BaseURL, "/") + "/" + name, nil
+}
@@ -0,0 +1,55 @@
+//go:build !lambdahttpadapter.partial || (lambdahttpadapter.partial && lambdahttpadapter.apigwv1)
+
+package handler
+
+import (
+	"context"
+	"github.com/aws/aws-lambda-go/events"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileStore(t *testing.T) {
+	dir := t.TempDir()
+	body := strings.Repeat("a", 2048)
+
+	h := NewAPIGatewayV1Handler(func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+		w.Header().Set("Content-Type", "text/csv")
+		_, _ = w.Write([]byte(body))
+		return nil
+	}, WithMaxResponseSize(1024), WithLargeResponseStore(FileStore{Dir: dir, BaseURL: "https://files.example.com/"}, http.StatusFound))
+
+	res, err := h(context.Background(), events.APIGatewayProxyRequest{HTTPMethod: http.MethodGet, Path: "/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	location := res.Headers["Location"]
+	if res.StatusCode != http.StatusFound || !strings.HasPrefix(location, "https://files.example.com/") || !strings.HasSuffix(location, ".csv") {
+		t.Fatalf("unexpected redirect: %d %q", res.StatusCode, location)
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, strings.TrimPrefix(location, "https://files.example.com/")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(b) != body {
+		t.Errorf("expected the body to be stored, got %d bytes", len(b))
+	}
+}
+
+func TestLargeResponseStoreErrorStatus(t *testing.T) {
+	h := NewAPIGatewayV1Handler(func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(strings.Repeat("a", 2048)))
+		return nil
+	}, WithMaxResponseSize(1024), WithLargeResponseStore(FileStore{Dir: t.TempDir()}, 0))
+
+	if _, err := h(context.Background(), events.APIGatewayProxyRequest{HTTPMethod: http.MethodGet, Path: "/"}); err == nil {
+		t.Error("expected error responses not to be stored")
+	}
+}
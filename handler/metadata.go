@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"context"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+var coldStartContextKey httpContextKey = "github.com/its-felix/aws-lambda-go-http-adapter/handler/metadata::coldStartContextKey"
+
+// invoked is set to 1 once the first event was handled by this process
+var invoked int32
+
+// RequestMetadata contains the Lambda specific information about the current invocation
+type RequestMetadata struct {
+	// AWSRequestID is the request ID of the Lambda invocation
+	AWSRequestID string
+	// SourceRequestID is the request ID of the event source (API Gateway, Lambda Function URL or CloudFront), if present
+	SourceRequestID string
+	// Stage is the API Gateway stage, if present
+	Stage string
+	// APIID is the ID of the API Gateway API or Lambda Function URL, if present
+	APIID string
+	// AccountID is the AWS account ID of the event source, or the account of the function if not present in the event
+	AccountID string
+	// Deadline is the deadline of the Lambda invocation, zero if not known
+	Deadline time.Time
+	// ColdStart is true for the first event handled by this process
+	ColdStart bool
+}
+
+// RemainingTime returns the time until the deadline of the Lambda invocation, or 0 if not known
+func (m RequestMetadata) RemainingTime() time.Duration {
+	if m.Deadline.IsZero() {
+		return 0
+	}
+
+	return time.Until(m.Deadline)
+}
+
+// requestMetadataSource is implemented by the event types of this package which contain request metadata
+type requestMetadataSource interface {
+	requestMetadata(m *RequestMetadata)
+}
+
+// amznHeadersDisallowed is implemented by the event types whose event source rejects response headers with the X-Amzn- prefix
+type amznHeadersDisallowed interface {
+	disallowsAmznHeaders()
+}
+
+// withColdStart marks the context of the first event handled by this process
+func withColdStart(ctx context.Context) context.Context {
+	return context.WithValue(ctx, coldStartContextKey, atomic.CompareAndSwapInt32(&invoked, 0, 1))
+}
+
+// GetRequestMetadata returns the metadata of the current invocation.
+// It returns false if the context was not created by a handler of this package.
+func GetRequestMetadata(ctx context.Context) (RequestMetadata, bool) {
+	coldStart, ok := ctx.Value(coldStartContextKey).(bool)
+	if !ok {
+		return RequestMetadata{}, false
+	}
+
+	m := RequestMetadata{ColdStart: coldStart}
+	m.Deadline, _ = ctx.Deadline()
+
+	switch event := GetSourceEvent(ctx).(type) {
+	case events.APIGatewayProxyRequest:
+		m.SourceRequestID = event.RequestContext.RequestID
+		m.Stage = event.RequestContext.Stage
+		m.APIID = event.RequestContext.APIID
+		m.AccountID = event.RequestContext.AccountID
+	case events.APIGatewayV2HTTPRequest:
+		m.SourceRequestID = event.RequestContext.RequestID
+		m.Stage = event.RequestContext.Stage
+		m.APIID = event.RequestContext.APIID
+		m.AccountID = event.RequestContext.AccountID
+	case events.APIGatewayWebsocketProxyRequest:
+		m.SourceRequestID = event.RequestContext.RequestID
+		m.Stage = event.RequestContext.Stage
+		m.APIID = event.RequestContext.APIID
+		m.AccountID = event.RequestContext.AccountID
+	case events.LambdaFunctionURLRequest:
+		m.SourceRequestID = event.RequestContext.RequestID
+		m.APIID = event.RequestContext.APIID
+		m.AccountID = event.RequestContext.AccountID
+	case requestMetadataSource:
+		event.requestMetadata(&m)
+	}
+
+	if lc, ok := lambdacontext.FromContext(ctx); ok {
+		m.AWSRequestID = lc.AwsRequestID
+
+		// arn:aws:lambda:<region>:<account>:function:<name>
+		if parts := strings.Split(lc.InvokedFunctionArn, ":"); m.AccountID == "" && len(parts) > 4 {
+			m.AccountID = parts[4]
+		}
+	}
+
+	return m, true
+}
+
+// WithRequestIDHeaders adds the X-Request-Id (the request ID of the event source, or the Lambda request ID if not present)
+// and X-Amzn-RequestId (the Lambda request ID) headers to all responses, unless they were set by the adapter.
+// X-Amzn-RequestId is not added to Lambda@Edge responses, as CloudFront rejects the header.
+func WithRequestIDHeaders() Option {
+	return func(o *options) {
+		o.requestIDHeaders = true
+	}
+}
+
+func (o *options) setRequestIDHeaders(ctx context.Context, headers http.Header) {
+	if !o.requestIDHeaders {
+		return
+	}
+
+	m, ok := GetRequestMetadata(ctx)
+	if !ok {
+		return
+	}
+
+	requestID := m.SourceRequestID
+	if requestID == "" {
+		requestID = m.AWSRequestID
+	}
+
+	if requestID != "" && !hasHeader(headers, "X-Request-Id") {
+		headers["X-Request-Id"] = []string{requestID}
+	}
+
+	if _, ok := GetSourceEvent(ctx).(amznHeadersDisallowed); ok {
+		return
+	}
+
+	if m.AWSRequestID != "" && !hasHeader(headers, "X-Amzn-RequestId") {
+		// set directly to keep the casing used by AWS
+		headers["X-Amzn-RequestId"] = []string{m.AWSRequestID}
+	}
+}
@@ -0,0 +1,99 @@
+//go:build !lambdahttpadapter.partial || (lambdahttpadapter.partial && lambdahttpadapter.alb && lambdahttpadapter.apigwv2)
+
+package handler
+
+import (
+	"context"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetRequestMetadata(t *testing.T) {
+	atomic.StoreInt32(&invoked, 0)
+
+	var metadata []RequestMetadata
+	h := NewAPIGatewayV2Handler(func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+		m, ok := GetRequestMetadata(r.Context())
+		if !ok {
+			t.Error("expected the request metadata to be available")
+		}
+
+		metadata = append(metadata, m)
+		return nil
+	}, WithRequestIDHeaders())
+
+	deadline := time.Now().Add(time.Minute)
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+
+	ctx = lambdacontext.NewContext(ctx, &lambdacontext.LambdaContext{
+		AwsRequestID:       "c6af9ac6-7b61-11e6-9a41-93e8deadbeef",
+		InvokedFunctionArn: "arn:aws:lambda:eu-central-1:210987654321:function:example",
+	})
+
+	event := events.APIGatewayV2HTTPRequest{
+		RawPath: "/",
+		RequestContext: events.APIGatewayV2HTTPRequestContext{
+			RequestID: "JKJaXmPLvHcESHA=",
+			Stage:     "$default",
+			APIID:     "0dhg9709da",
+			AccountID: "123456789012",
+			HTTP: events.APIGatewayV2HTTPRequestContextHTTPDescription{
+				Method: http.MethodGet,
+			},
+		},
+	}
+
+	for i := 0; i < 2; i++ {
+		res, err := h(ctx, event)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if res.Headers["X-Request-Id"] != "JKJaXmPLvHcESHA=" || res.Headers["X-Amzn-RequestId"] != "c6af9ac6-7b61-11e6-9a41-93e8deadbeef" {
+			t.Errorf("unexpected request id headers: %v", res.Headers)
+		}
+	}
+
+	m := metadata[0]
+	if m.AWSRequestID != "c6af9ac6-7b61-11e6-9a41-93e8deadbeef" || m.SourceRequestID != "JKJaXmPLvHcESHA=" || m.Stage != "$default" || m.APIID != "0dhg9709da" || m.AccountID != "123456789012" {
+		t.Errorf("unexpected metadata: %+v", m)
+	}
+
+	if !m.Deadline.Equal(deadline) || m.RemainingTime() <= 0 || m.RemainingTime() > time.Minute {
+		t.Errorf("unexpected deadline: %v", m.Deadline)
+	}
+
+	if !metadata[0].ColdStart || metadata[1].ColdStart {
+		t.Errorf("expected only the first invocation to be a cold start, got %v and %v", metadata[0].ColdStart, metadata[1].ColdStart)
+	}
+}
+
+func TestRequestIDHeadersFallback(t *testing.T) {
+	h := NewALBHandler(func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+		w.Header().Set("X-Request-Id", "from-adapter")
+		return nil
+	}, WithRequestIDHeaders())
+
+	ctx := lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{
+		AwsRequestID:       "c6af9ac6-7b61-11e6-9a41-93e8deadbeef",
+		InvokedFunctionArn: "arn:aws:lambda:eu-central-1:210987654321:function:example",
+	})
+
+	res, err := h(ctx, events.ALBTargetGroupRequest{HTTPMethod: http.MethodGet, Path: "/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Headers["X-Request-Id"] != "from-adapter" || res.Headers["X-Amzn-RequestId"] != "c6af9ac6-7b61-11e6-9a41-93e8deadbeef" {
+		t.Errorf("unexpected request id headers: %v", res.Headers)
+	}
+
+	if _, ok := GetRequestMetadata(context.Background()); ok {
+		t.Error("expected no metadata for a context not created by a handler")
+	}
+}
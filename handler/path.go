@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+var originalPathContextKey httpContextKey = "github.com/its-felix/aws-lambda-go-http-adapter/handler/path::originalPathContextKey"
+
+// trimPathPrefix removes prefix from path if it matches complete path segments
+func trimPathPrefix(path, prefix string) (string, bool) {
+	if path == prefix {
+		return "/", true
+	} else if strings.HasPrefix(path, prefix+"/") {
+		return path[len(prefix):], true
+	}
+
+	return path, false
+}
+
+func (o *options) stripPath(req *http.Request, stage string) *http.Request {
+	if !o.stripStage && o.basePath == "" {
+		return req
+	}
+
+	prefixes := make([]string, 0, 2)
+	if o.stripStage && stage != "" && stage != "$default" {
+		prefixes = append(prefixes, "/"+stage)
+	}
+
+	if o.basePath != "" {
+		prefixes = append(prefixes, o.basePath)
+	}
+
+	originalPath := req.URL.Path
+	path, rawPath := req.URL.Path, req.URL.EscapedPath()
+
+	for _, prefix := range prefixes {
+		var ok bool
+		if path, ok = trimPathPrefix(path, prefix); ok {
+			rawPath, _ = trimPathPrefix(rawPath, (&url.URL{Path: prefix}).EscapedPath())
+		}
+	}
+
+	req = req.WithContext(context.WithValue(req.Context(), originalPathContextKey, originalPath))
+	req.URL.Path = path
+	req.URL.RawPath = rawPath
+	req.RequestURI = req.URL.RequestURI()
+
+	return req
+}
+
+// GetOriginalPath returns the request path before the stage or base path was removed using WithStripStage or WithStripBasePath
+func GetOriginalPath(ctx context.Context) (string, bool) {
+	path, ok := ctx.Value(originalPathContextKey).(string)
+	return path, ok
+}
@@ -0,0 +1,88 @@
+//go:build !lambdahttpadapter.partial || (lambdahttpadapter.partial && lambdahttpadapter.apigwv1 && lambdahttpadapter.apigwv2)
+
+package handler
+
+import (
+	"context"
+	"github.com/aws/aws-lambda-go/events"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestStripPath(t *testing.T) {
+	tests := map[string]struct {
+		opts         []Option
+		stage        string
+		path         string
+		expectedPath string
+		expectedURI  string
+	}{
+		"disabled":            {nil, "prod", "/prod/users", "/prod/users", "/prod/users?key=value"},
+		"stage":               {[]Option{WithStripStage()}, "prod", "/prod/users", "/users", "/users?key=value"},
+		"stage only":          {[]Option{WithStripStage()}, "prod", "/prod", "/", "/?key=value"},
+		"stage partial":       {[]Option{WithStripStage()}, "prod", "/production/users", "/production/users", "/production/users?key=value"},
+		"default stage":       {[]Option{WithStripStage()}, "$default", "/users", "/users", "/users?key=value"},
+		"base path":           {[]Option{WithStripBasePath("/api/")}, "prod", "/api/users", "/users", "/users?key=value"},
+		"stage and base path": {[]Option{WithStripStage(), WithStripBasePath("api")}, "prod", "/prod/api/users", "/users", "/users?key=value"},
+		"escaped":             {[]Option{WithStripBasePath("api")}, "prod", "/api/a%2Fb", "/a/b", "/a%2Fb?key=value"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var path, uri, originalPath string
+			var ok bool
+
+			h := NewAPIGatewayV2Handler(func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+				path, uri = r.URL.Path, r.RequestURI
+				originalPath, ok = GetOriginalPath(r.Context())
+				return nil
+			}, tt.opts...)
+
+			_, err := h(context.Background(), events.APIGatewayV2HTTPRequest{
+				RawPath:               tt.path,
+				QueryStringParameters: map[string]string{"key": "value"},
+				RequestContext: events.APIGatewayV2HTTPRequestContext{
+					Stage: tt.stage,
+					HTTP: events.APIGatewayV2HTTPRequestContextHTTPDescription{
+						Method: http.MethodGet,
+					},
+				},
+			})
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if path != tt.expectedPath || uri != tt.expectedURI {
+				t.Errorf("expected path %q and request uri %q, got %q and %q", tt.expectedPath, tt.expectedURI, path, uri)
+			}
+
+			if expected, _ := url.PathUnescape(tt.path); len(tt.opts) > 0 && (!ok || originalPath != expected) {
+				t.Errorf("expected the original path to be available, got %q", originalPath)
+			}
+		})
+	}
+}
+
+func TestStripBasePathAPIGatewayV1(t *testing.T) {
+	var path, originalPath string
+	h := NewAPIGatewayV1Handler(func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+		path = r.URL.Path
+		originalPath, _ = GetOriginalPath(ctx)
+		return nil
+	}, WithStripBasePath("api"))
+
+	_, err := h(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod: http.MethodGet,
+		Path:       "/api/users",
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if path != "/users" || originalPath != "/api/users" {
+		t.Errorf("unexpected path %q (original %q)", path, originalPath)
+	}
+}
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+)
+
+var routeContextKey httpContextKey = "github.com/its-felix/aws-lambda-go-http-adapter/handler/route::routeContextKey"
+
+// Route describes the route matched by API Gateway
+type Route struct {
+	// Key is the matched resource for API Gateway V1 (for example /users/{id}) or the matched route key for API Gateway V2 (for example GET /users/{id})
+	Key            string
+	PathParameters map[string]string
+}
+
+// withRoute makes the route available using GetRoute and, on Go 1.22 and later, the path parameters using http.Request.PathValue
+func withRoute(ctx context.Context, key string, pathParameters map[string]string) context.Context {
+	if pathParameters == nil {
+		pathParameters = make(map[string]string)
+	}
+
+	return context.WithValue(ctx, routeContextKey, Route{key, pathParameters})
+}
+
+func setPathValues(req *http.Request) {
+	if route, ok := GetRoute(req.Context()); ok {
+		for k, v := range route.PathParameters {
+			setPathValue(req, k, v)
+		}
+	}
+}
+
+// GetRoute returns the route matched by API Gateway
+func GetRoute(ctx context.Context) (Route, bool) {
+	route, ok := ctx.Value(routeContextKey).(Route)
+	return route, ok
+}
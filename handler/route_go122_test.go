@@ -0,0 +1,37 @@
+//go:build go1.22 && (!lambdahttpadapter.partial || (lambdahttpadapter.partial && lambdahttpadapter.apigwv2))
+
+package handler
+
+import (
+	"context"
+	"github.com/aws/aws-lambda-go/events"
+	"net/http"
+	"testing"
+)
+
+func TestPathValue(t *testing.T) {
+	var id string
+	adapter := func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+		id = r.PathValue("id")
+		return nil
+	}
+
+	_, err := NewAPIGatewayV2Handler(adapter)(context.Background(), events.APIGatewayV2HTTPRequest{
+		RouteKey:       "GET /users/{id}",
+		RawPath:        "/users/123",
+		PathParameters: map[string]string{"id": "123"},
+		RequestContext: events.APIGatewayV2HTTPRequestContext{
+			HTTP: events.APIGatewayV2HTTPRequestContextHTTPDescription{
+				Method: http.MethodGet,
+			},
+		},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if id != "123" {
+		t.Errorf("expected the path value to be set, got %q", id)
+	}
+}
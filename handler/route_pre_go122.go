@@ -0,0 +1,8 @@
+//go:build !go1.22
+
+package handler
+
+import "net/http"
+
+func setPathValue(req *http.Request, name, value string) {
+}
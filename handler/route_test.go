@@ -0,0 +1,55 @@
+//go:build !lambdahttpadapter.partial || (lambdahttpadapter.partial && lambdahttpadapter.apigwv1 && lambdahttpadapter.apigwv2)
+
+package handler
+
+import (
+	"context"
+	"github.com/aws/aws-lambda-go/events"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestGetRoute(t *testing.T) {
+	var route Route
+	var ok bool
+
+	adapter := func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+		route, ok = GetRoute(r.Context())
+		return nil
+	}
+
+	_, err := NewAPIGatewayV1Handler(adapter)(context.Background(), events.APIGatewayProxyRequest{
+		Resource:       "/users/{id}",
+		Path:           "/users/123",
+		HTTPMethod:     http.MethodGet,
+		PathParameters: map[string]string{"id": "123"},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !ok || route.Key != "/users/{id}" || !reflect.DeepEqual(route.PathParameters, map[string]string{"id": "123"}) {
+		t.Errorf("unexpected route: %+v", route)
+	}
+
+	_, err = NewAPIGatewayV2Handler(adapter)(context.Background(), events.APIGatewayV2HTTPRequest{
+		RouteKey:       "GET /users/{id}",
+		RawPath:        "/users/123",
+		PathParameters: map[string]string{"id": "123"},
+		RequestContext: events.APIGatewayV2HTTPRequestContext{
+			HTTP: events.APIGatewayV2HTTPRequestContextHTTPDescription{
+				Method: http.MethodGet,
+			},
+		},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !ok || route.Key != "GET /users/{id}" || !reflect.DeepEqual(route.PathParameters, map[string]string{"id": "123"}) {
+		t.Errorf("unexpected route: %+v", route)
+	}
+}
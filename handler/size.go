@@ -0,0 +1,206 @@
+package handler
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"unicode/utf8"
+)
+
+// DefaultMaxResponseSize is the payload limit of synchronous Lambda invocations
+const DefaultMaxResponseSize = 6 * 1024 * 1024
+
+// ErrResponseTooLarge is matched by the error passed to the ErrorResponder if a buffered response exceeds the maximum size
+var ErrResponseTooLarge = errors.New("the response exceeds the maximum size")
+
+// OversizedResponseHook is called with the complete response if it exceeds the maximum size, the returned response is sent instead
+type OversizedResponseHook func(ctx context.Context, statusCode int, headers http.Header, body []byte) (int, http.Header, []byte, error)
+
+// WithMaxResponseSize sets the maximum size of buffered responses (DefaultMaxResponseSize by default).
+// The size is measured on the serialized response returned to Lambda, including headers and base64 encoding.
+// Responses exceeding it fail with an error matching ErrResponseTooLarge, writes of the adapter fail as soon as the limit is exceeded.
+// Use WithOversizedResponseHook or WithTruncateOversizedResponses to handle them differently.
+// A limit of 0 or less disables the check.
+func WithMaxResponseSize(limit int) Option {
+	return func(o *options) {
+		o.maxResponseSize = limit
+	}
+}
+
+// WithOversizedResponseHook calls the hook with the complete response if it exceeds the maximum size
+func WithOversizedResponseHook(hook OversizedResponseHook) Option {
+	return func(o *options) {
+		o.oversizedResponseHook = hook
+	}
+}
+
+// WithTruncateOversizedResponses truncates the body of responses exceeding the maximum size so that they fit.
+// Bodies compressed by the adapter can't be truncated, these responses fail instead.
+func WithTruncateOversizedResponses() Option {
+	return func(o *options) {
+		o.truncateOversizedResponses = true
+	}
+}
+
+func newResponseTooLargeError(size, limit int) error {
+	return &handlerError{kind: ErrResponseTooLarge, err: fmt.Errorf("%d bytes exceed the limit of %d bytes", size, limit)}
+}
+
+// minSerializedSize returns a lower bound of the serialized size of a response, every header and body byte is serialized at least once
+func minSerializedSize(headers http.Header, bodySize int) int {
+	size := bodySize
+	for k, values := range headers {
+		size += len(k)
+		for _, v := range values {
+			size += len(v)
+		}
+	}
+
+	return size
+}
+
+// maxSerializedSize returns an upper bound of the serialized size of a response, whether the body is sent as escaped text or base64 encoded
+func maxSerializedSize(headers http.Header, body []byte) int {
+	// status code, field names and the other fixed parts of the response
+	size := 512
+	for k, values := range headers {
+		for _, v := range values {
+			// Lambda@Edge serializes the key twice, every character may be escaped using \uXXXX
+			size += 6*(2*len(k)+len(v)) + 16
+		}
+	}
+
+	bodySize := base64.StdEncoding.EncodedLen(len(body))
+	if escaped := maxEscapedSize(body); escaped > bodySize {
+		bodySize = escaped
+	}
+
+	return size + bodySize
+}
+
+// maxEscapedSize returns the size of the body as a JSON string, assuming the HTML characters are escaped as well
+func maxEscapedSize(body []byte) int {
+	size := 2
+	for i := 0; i < len(body); {
+		if b := body[i]; b < utf8.RuneSelf {
+			if b < 0x20 || b == '"' || b == '\\' || b == '<' || b == '>' || b == '&' {
+				size += 6
+			} else {
+				size++
+			}
+
+			i++
+			continue
+		}
+
+		r, n := utf8.DecodeRune(body[i:])
+		if r == utf8.RuneError || r == '\u2028' || r == '\u2029' {
+			size += 6
+		} else {
+			size += n
+		}
+
+		i += n
+	}
+
+	return size
+}
+
+func serializedSize(v any) (int, error) {
+	b, err := json.Marshal(v)
+	return len(b), err
+}
+
+// finalizeResponse compresses the body depending on the encoding, creates the response and applies the maximum response size.
+// The hook and the truncation of oversized responses are applied to the uncompressed response.
+func finalizeResponse[Out any](ctx context.Context, o *options, newResponse func(statusCode int, headers http.Header, body []byte, o *options) Out, encoding string, statusCode int, headers http.Header, body []byte) (Out, error) {
+	sentHeaders, sentBody := headers, body
+	compressed := o.shouldCompress(encoding, statusCode, headers, len(body))
+	if compressed {
+		var err error
+		sentHeaders = headers.Clone()
+		if sentBody, err = compress(encoding, sentHeaders, body); err != nil {
+			return respondError(ctx, o, newResponse, ErrResponseBody, err)
+		}
+	}
+
+	res := newResponse(statusCode, sentHeaders, sentBody, o)
+	if o.maxResponseSize <= 0 || maxSerializedSize(sentHeaders, sentBody) <= o.maxResponseSize {
+		// only responses close to the limit are serialized to measure their exact size
+		return res, nil
+	}
+
+	size, err := serializedSize(res)
+	if err != nil {
+		return respondError(ctx, o, newResponse, ErrResponseBody, err)
+	} else if size <= o.maxResponseSize {
+		return res, nil
+	}
+
+	if o.oversizedResponseHook != nil {
+		statusCode, headers, body, err = o.oversizedResponseHook(ctx, statusCode, headers, body)
+		if err != nil {
+			return respondError(ctx, o, newResponse, ErrResponseBody, err)
+		}
+
+		res = newResponse(statusCode, headers, body, o)
+		if size, err = serializedSize(res); err != nil {
+			return respondError(ctx, o, newResponse, ErrResponseBody, err)
+		} else if size <= o.maxResponseSize {
+			return res, nil
+		}
+	} else if o.truncateOversizedResponses && !hasHeader(headers, "Content-Encoding") {
+		if compressed {
+			// the body is truncated uncompressed, which has a different serialized size
+			if size, err = serializedSize(newResponse(statusCode, headers, body, o)); err != nil {
+				return respondError(ctx, o, newResponse, ErrResponseBody, err)
+			}
+		}
+
+		return truncateResponse(ctx, o, newResponse, statusCode, headers, body, size)
+	}
+
+	return respondError(ctx, o, newResponse, ErrResponseBody, newResponseTooLargeError(size, o.maxResponseSize))
+}
+
+// truncateResponse shortens the body until the serialized response fits the maximum size.
+// Every step shortens the body proportionally to the serialized size of the body, which accounts for escaping and base64 encoding.
+func truncateResponse[Out any](ctx context.Context, o *options, newResponse func(statusCode int, headers http.Header, body []byte, o *options) Out, statusCode int, headers http.Header, body []byte, size int) (Out, error) {
+	overhead, err := serializedSize(newResponse(statusCode, headers, nil, o))
+	if err != nil {
+		return respondError(ctx, o, newResponse, ErrResponseBody, err)
+	} else if overhead >= o.maxResponseSize {
+		return respondError(ctx, o, newResponse, ErrResponseBody, newResponseTooLargeError(overhead, o.maxResponseSize))
+	}
+
+	isText := utf8.Valid(body)
+	n := len(body)
+
+	for {
+		next := int(int64(n) * int64(o.maxResponseSize-overhead) / int64(size-overhead))
+		if next >= n {
+			next = n - 1
+		}
+
+		// don't split a character, which would turn a text body into a base64 encoded one
+		for isText && next > 0 && !utf8.RuneStart(body[next]) {
+			next--
+		}
+
+		n = next
+		if hasHeader(headers, "Content-Length") {
+			headers.Set("Content-Length", strconv.Itoa(n))
+		}
+
+		res := newResponse(statusCode, headers, body[:n], o)
+		if size, err = serializedSize(res); err != nil {
+			return respondError(ctx, o, newResponse, ErrResponseBody, err)
+		} else if size <= o.maxResponseSize || n == 0 {
+			return res, nil
+		}
+	}
+}
@@ -0,0 +1,39 @@
+//go:build !lambdahttpadapter.partial || (lambdahttpadapter.partial && lambdahttpadapter.apigwv1 && lambdahttpadapter.apigwv2 && lambdahttpadapter.functionurl)
+
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestMaxSerializedSize(t *testing.T) {
+	headers := http.Header{
+		"Content-Type": {"text/html; charset=utf-8"},
+		"Set-Cookie":   {"a=<1>", "b=\"2\""},
+	}
+
+	tests := map[string][]byte{
+		"empty":   nil,
+		"text":    []byte(strings.Repeat("hello world\n", 100)),
+		"escaped": []byte(strings.Repeat("<\"&\\\x01>", 100)),
+		"unicode": []byte(strings.Repeat("äöü ", 100)),
+		"binary":  bytes.Repeat([]byte{0xff, 0x00}, 100),
+	}
+
+	for name, body := range tests {
+		t.Run(name, func(t *testing.T) {
+			bound := maxSerializedSize(headers, body)
+
+			for _, o := range []*options{newOptions(nil), newOptions([]Option{WithAlwaysBase64()}), newOptions([]Option{WithBase64Predicate(func(http.Header, []byte) bool { return false })})} {
+				for _, res := range []any{newApiGwV1Response(200, headers, body, o), newApiGwV2Response(200, headers, body, o), newFunctionURLResponse(200, headers, body, o)} {
+					if size, _ := serializedSize(res); size > bound {
+						t.Errorf("%T: expected at most %d bytes, got %d bytes", res, bound, size)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+//go:build !lambdahttpadapter.partial || (lambdahttpadapter.partial && lambdahttpadapter.alb && lambdahttpadapter.apigwv2)
+
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/its-felix/aws-lambda-go-http-adapter/handler"
+	"github.com/its-felix/aws-lambda-go-http-adapter/handlertest"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func writeBody(body []byte) handler.AdapterFunc {
+	return func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+		_, err := w.Write(body)
+		return err
+	}
+}
+
+func assertSerializedSize(t *testing.T, res any, limit int) {
+	t.Helper()
+
+	b, err := json.Marshal(res)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(b) > limit {
+		t.Errorf("expected the serialized response to fit %d bytes, got %d bytes", limit, len(b))
+	}
+}
+
+func TestMaxResponseSizeFail(t *testing.T) {
+	var writeErr error
+	h := handler.NewAPIGatewayV2Handler(func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+		_, writeErr = w.Write(bytes.Repeat([]byte("a"), 2048))
+		return nil
+	}, handler.WithMaxResponseSize(1024))
+
+	_, err := h(context.Background(), handlertest.APIGatewayV2().Build())
+	if !errors.Is(err, handler.ErrResponseTooLarge) {
+		t.Errorf("expected the invocation to fail with ErrResponseTooLarge, got %v", err)
+	}
+
+	if !errors.Is(writeErr, handler.ErrResponseTooLarge) {
+		t.Errorf("expected the write to fail, got %v", writeErr)
+	}
+}
+
+func TestMaxResponseSizeFailSerialized(t *testing.T) {
+	// fits as raw bytes, but not once base64 encoded
+	h := handler.NewAPIGatewayV2Handler(writeBody(bytes.Repeat([]byte{0xff}, 900)), handler.WithMaxResponseSize(1024), handler.WithErrorResponder(handler.ProblemDetailsErrorResponder))
+
+	res, err := h(context.Background(), handlertest.APIGatewayV2().Build())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.StatusCode != http.StatusBadGateway || res.Headers["Content-Type"] != "application/problem+json" {
+		t.Errorf("expected a problem details response, got %d %v", res.StatusCode, res.Headers)
+	}
+}
+
+func TestMaxResponseSizeDefault(t *testing.T) {
+	body := bytes.Repeat([]byte("a"), handler.DefaultMaxResponseSize)
+
+	if _, err := handler.NewAPIGatewayV2Handler(writeBody(body))(context.Background(), handlertest.APIGatewayV2().Build()); !errors.Is(err, handler.ErrResponseTooLarge) {
+		t.Errorf("expected the default limit to apply, got %v", err)
+	}
+
+	res, err := handler.NewAPIGatewayV2Handler(writeBody(body), handler.WithMaxResponseSize(0))(context.Background(), handlertest.APIGatewayV2().Build())
+	if err != nil || len(res.Body) != len(body) {
+		t.Errorf("expected the limit to be disabled, got %d bytes (%v)", len(res.Body), err)
+	}
+}
+
+func TestMaxResponseSizeTruncate(t *testing.T) {
+	tests := map[string][]byte{
+		"text":      []byte(strings.Repeat("äöü", 1000)),
+		"binary":    bytes.Repeat([]byte{0xff}, 3000),
+		"escaped":   bytes.Repeat([]byte("\""), 3000),
+		"too small": bytes.Repeat([]byte("a"), 1020),
+	}
+
+	for name, body := range tests {
+		t.Run(name, func(t *testing.T) {
+			h := handler.NewAPIGatewayV2Handler(writeBody(body), handler.WithMaxResponseSize(1024), handler.WithTruncateOversizedResponses())
+
+			res, err := h(context.Background(), handlertest.APIGatewayV2().Build())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assertSerializedSize(t, res, 1024)
+
+			if name == "text" && (res.IsBase64Encoded || !strings.HasPrefix(string(body), res.Body)) {
+				t.Errorf("expected a text prefix of the body, got %q", res.Body)
+			}
+
+			if cl, _ := strconv.Atoi(res.Headers["Content-Length"]); res.Body == "" || (!res.IsBase64Encoded && cl != len(res.Body)) {
+				t.Errorf("unexpected Content-Length %d for a body of %d bytes", cl, len(res.Body))
+			}
+		})
+	}
+}
+
+func TestOversizedResponseHook(t *testing.T) {
+	var hookBody []byte
+	h := handler.NewALBHandler(writeBody(bytes.Repeat([]byte("a"), 4096)), handler.WithMaxResponseSize(1024), handler.WithOversizedResponseHook(func(ctx context.Context, statusCode int, headers http.Header, body []byte) (int, http.Header, []byte, error) {
+		hookBody = body
+
+		h := make(http.Header)
+		h.Set("Location", "https://example.com/large")
+		return http.StatusSeeOther, h, nil, nil
+	}))
+
+	res, err := h(context.Background(), handlertest.ALB().Build())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(hookBody) != 4096 {
+		t.Errorf("expected the hook to receive the complete body, got %d bytes", len(hookBody))
+	}
+
+	if res.StatusCode != http.StatusSeeOther || res.Headers["Location"] != "https://example.com/large" {
+		t.Errorf("expected the response of the hook, got %d %v", res.StatusCode, res.Headers)
+	}
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ErrTimeout is matched by the error passed to the ErrorResponder of WithTimeout
+var ErrTimeout = errors.New("the request did not complete before the timeout")
+
+func newTimeoutError() error {
+	return &handlerError{kind: ErrTimeout, err: context.DeadlineExceeded}
+}
+
+// WithTimeout cancels the context of the request once the deadline of the Lambda invocation minus margin is reached,
+// leaving time to respond before Lambda stops the invocation.
+// If the response wasn't sent yet, the handler responds using the given ErrorResponder, or with 504 Gateway Timeout if nil.
+// Buffered responses are never sent partially. For streaming responses whose headers were already sent, the body is closed with an error instead.
+// Writes of the adapter after the timeout fail with http.ErrHandlerTimeout.
+func WithTimeout(margin time.Duration, responder ErrorResponder) Option {
+	if responder == nil {
+		responder = GatewayTimeoutResponder
+	}
+
+	return func(o *options) {
+		o.timeoutMargin = margin
+		o.timeoutResponder = responder
+	}
+}
+
+// GatewayTimeoutResponder responds with 504 Gateway Timeout and a plain text body
+func GatewayTimeoutResponder(ctx context.Context, event any, err error) (int, http.Header, []byte) {
+	body := []byte(http.StatusText(http.StatusGatewayTimeout))
+
+	headers := make(http.Header)
+	headers.Set("Content-Type", "text/plain; charset=utf-8")
+	headers.Set("Content-Length", strconv.Itoa(len(body)))
+
+	return http.StatusGatewayTimeout, headers, body
+}
+
+// timeoutContext returns a context which is canceled once the timeout of WithTimeout is reached.
+// The returned bool is false if WithTimeout is not used or the context has no deadline.
+func (o *options) timeoutContext(ctx context.Context) (context.Context, context.CancelFunc, bool) {
+	deadline, ok := ctx.Deadline()
+	if o.timeoutResponder == nil || !ok {
+		ctx, cancel := context.WithCancel(ctx)
+		return ctx, cancel, false
+	}
+
+	ctx, cancel := context.WithDeadline(ctx, deadline.Add(-o.timeoutMargin))
+	return ctx, cancel, true
+}
+
+// runAdapter passes the request to the adapter. If WithTimeout is used, the adapter runs in a separate goroutine
+// and an error matching ErrTimeout is returned once the timeout is reached, even if the adapter didn't return yet.
+// Panics of the adapter are propagated unless the timeout was reached before.
+func (o *options) runAdapter(ctx context.Context, adapter AdapterFunc, req *http.Request, w *bufferedResponseWriter) error {
+	w.prepare(req, o)
+
+	timeoutCtx, cancel, ok := o.timeoutContext(ctx)
+	defer cancel()
+
+	if !ok {
+		return adapter(ctx, req, w)
+	}
+
+	ctx = timeoutCtx
+	req = req.WithContext(ctx)
+	w.setTimeoutContext(ctx)
+
+	// buffered, so that the goroutine never blocks once this function returned
+	errCh := make(chan error, 1)
+	panicCh := make(chan any, 1)
+
+	go func() {
+		defer func() {
+			if panicV := recover(); panicV != nil {
+				panicCh <- panicV
+			}
+		}()
+
+		errCh <- adapter(ctx, req, w)
+	}()
+
+	select {
+	case err := <-errCh:
+		// writes already failed if the timeout was reached before the adapter returned
+		if timeoutReached(ctx) {
+			w.timeout()
+			return newTimeoutError()
+		}
+
+		return err
+	case panicV := <-panicCh:
+		panic(panicV)
+	case <-ctx.Done():
+		w.timeout()
+		return newTimeoutError()
+	}
+}
+
+// timeoutReached reports whether the timeout of WithTimeout was reached, ctx is nil if WithTimeout is not used
+func timeoutReached(ctx context.Context) bool {
+	return ctx != nil && ctx.Err() == context.DeadlineExceeded
+}
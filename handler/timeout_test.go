@@ -0,0 +1,149 @@
+//go:build !lambdahttpadapter.partial || (lambdahttpadapter.partial && lambdahttpadapter.apigwv2 && lambdahttpadapter.functionurl)
+
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"github.com/its-felix/aws-lambda-go-http-adapter/handler"
+	"github.com/its-felix/aws-lambda-go-http-adapter/handlertest"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func newTimeoutTestContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
+	t.Cleanup(cancel)
+
+	return ctx
+}
+
+func TestTimeoutBuffered(t *testing.T) {
+	writeErr := make(chan error, 1)
+	h := handler.NewFunctionURLHandler(func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+		_, _ = w.Write([]byte("partial"))
+
+		<-r.Context().Done()
+		_, err := w.Write([]byte("late"))
+		writeErr <- err
+
+		return nil
+	}, handler.WithTimeout(100*time.Millisecond, nil))
+
+	res, err := h(newTimeoutTestContext(t), handlertest.FunctionURL().Build())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.StatusCode != http.StatusGatewayTimeout || res.Body != "Gateway Timeout" {
+		t.Errorf("expected the timeout response, got %d %q", res.StatusCode, res.Body)
+	}
+
+	if err := <-writeErr; !errors.Is(err, http.ErrHandlerTimeout) {
+		t.Errorf("expected writes after the timeout to fail, got %v", err)
+	}
+}
+
+func TestTimeoutResponder(t *testing.T) {
+	h := handler.NewAPIGatewayV2Handler(func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, handler.WithTimeout(100*time.Millisecond, handler.ProblemDetailsErrorResponder))
+
+	res, err := h(newTimeoutTestContext(t), handlertest.APIGatewayV2().Build())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.StatusCode != http.StatusGatewayTimeout || res.Headers["Content-Type"] != "application/problem+json" {
+		t.Errorf("expected a problem details timeout response, got %d %v", res.StatusCode, res.Headers)
+	}
+}
+
+func TestTimeoutNotReached(t *testing.T) {
+	h := handler.NewFunctionURLHandler(func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+		_, _ = w.Write([]byte("hello world"))
+		return nil
+	}, handler.WithTimeout(100*time.Millisecond, nil))
+
+	res, err := h(newTimeoutTestContext(t), handlertest.FunctionURL().Build())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.StatusCode != http.StatusOK || res.Body != "hello world" {
+		t.Errorf("unexpected response: %d %q", res.StatusCode, res.Body)
+	}
+}
+
+func TestTimeoutPanic(t *testing.T) {
+	defer func() {
+		if v := recover(); v != "panic before the timeout" {
+			t.Errorf("expected the panic to be propagated, got %v", v)
+		}
+	}()
+
+	h := handler.NewFunctionURLHandler(func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+		panic("panic before the timeout")
+	}, handler.WithTimeout(100*time.Millisecond, nil))
+
+	_, _ = h(newTimeoutTestContext(t), handlertest.FunctionURL().Build())
+}
+
+func TestTimeoutStreaming(t *testing.T) {
+	h := handler.NewFunctionURLStreamingHandler(func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+		<-r.Context().Done()
+		return nil
+	}, handler.WithTimeout(100*time.Millisecond, nil))
+
+	res, err := h(newTimeoutTestContext(t), handlertest.FunctionURL().Build())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, _ := io.ReadAll(res.Body)
+	if res.StatusCode != http.StatusGatewayTimeout || string(b) != "Gateway Timeout" {
+		t.Errorf("expected the timeout response, got %d %q", res.StatusCode, b)
+	}
+}
+
+func TestTimeoutStreamingHeadersSent(t *testing.T) {
+	h := handler.NewFunctionURLStreamingHandler(func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+		_, _ = w.Write([]byte("partial"))
+
+		<-r.Context().Done()
+		return nil
+	}, handler.WithTimeout(100*time.Millisecond, nil))
+
+	res, err := h(newTimeoutTestContext(t), handlertest.FunctionURL().Build())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := io.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK || string(b) != "partial" {
+		t.Errorf("unexpected response: %d %q", res.StatusCode, b)
+	}
+
+	if !errors.Is(err, handler.ErrTimeout) {
+		t.Errorf("expected the body to be closed with the timeout error, got %v", err)
+	}
+}
+
+func TestTimeoutStreamingAdapterError(t *testing.T) {
+	h := handler.NewFunctionURLStreamingHandler(func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, handler.WithTimeout(100*time.Millisecond, nil))
+
+	res, err := h(newTimeoutTestContext(t), handlertest.FunctionURL().Build())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.StatusCode != http.StatusGatewayTimeout {
+		t.Errorf("expected the timeout response, got %d", res.StatusCode)
+	}
+}
@@ -0,0 +1,221 @@
+//go:build !lambdahttpadapter.partial || (lambdahttpadapter.partial && lambdahttpadapter.vpclattice)
+
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+var vpcLatticeIdentityContextKey httpContextKey = "github.com/its-felix/aws-lambda-go-http-adapter/handler/vpclattice::vpcLatticeIdentityContextKey"
+
+func init() {
+	registerAutoHandler(eventFormatVPCLatticeV1, handleVPCLatticeV1, newVPCLatticeResponse)
+	registerAutoHandler(eventFormatVPCLatticeV2, handleVPCLatticeV2, newVPCLatticeResponse)
+}
+
+// VPCLatticeV1Request is the payload sent by VPC Lattice to Lambda targets using the event structure version 1
+type VPCLatticeV1Request struct {
+	RawPath               string            `json:"raw_path"`
+	Method                string            `json:"method"`
+	Headers               map[string]string `json:"headers"`
+	QueryStringParameters map[string]string `json:"query_string_parameters"`
+	Body                  string            `json:"body"`
+	IsBase64Encoded       bool              `json:"is_base64_encoded"`
+}
+
+// VPCLatticeV2Request is the payload sent by VPC Lattice to Lambda targets using the event structure version 2
+type VPCLatticeV2Request struct {
+	Version               string                   `json:"version"`
+	Path                  string                   `json:"path"`
+	Method                string                   `json:"method"`
+	Headers               map[string][]string      `json:"headers"`
+	QueryStringParameters map[string][]string      `json:"queryStringParameters"`
+	Body                  string                   `json:"body"`
+	IsBase64Encoded       bool                     `json:"isBase64Encoded"`
+	RequestContext        VPCLatticeRequestContext `json:"requestContext"`
+}
+
+type VPCLatticeRequestContext struct {
+	ServiceNetworkARN string             `json:"serviceNetworkArn"`
+	ServiceARN        string             `json:"serviceArn"`
+	TargetGroupARN    string             `json:"targetGroupArn"`
+	Identity          VPCLatticeIdentity `json:"identity"`
+	Region            string             `json:"region"`
+	TimeEpoch         string             `json:"timeEpoch"`
+}
+
+// VPCLatticeIdentity describes the caller of a VPC Lattice service
+type VPCLatticeIdentity struct {
+	SourceVPCARN   string `json:"sourceVpcArn"`
+	Type           string `json:"type"`
+	Principal      string `json:"principal"`
+	PrincipalOrgID string `json:"principalOrgID"`
+	SessionName    string `json:"sessionName"`
+	X509SanDNS     string `json:"x509SanDns"`
+	X509SanNameCN  string `json:"x509SanNameCn"`
+	X509SubjectCN  string `json:"x509SubjectCn"`
+	X509IssuerOU   string `json:"x509IssuerOu"`
+	X509SanURI     string `json:"x509SanUri"`
+}
+
+// VPCLatticeResponse is the response expected by VPC Lattice for both event structure versions
+type VPCLatticeResponse struct {
+	StatusCode        int               `json:"statusCode"`
+	StatusDescription string            `json:"statusDescription,omitempty"`
+	Headers           map[string]string `json:"headers"`
+	Body              string            `json:"body,omitempty"`
+	IsBase64Encoded   bool              `json:"isBase64Encoded"`
+}
+
+func newVPCLatticeRequest(ctx context.Context, method, path string, query url.Values, headers http.Header, body string, isB64 bool) (*http.Request, error) {
+	rawQuery := query.Encode()
+
+	// the path may contain the raw query string if it could not be parsed into parameters
+	if p, q, ok := strings.Cut(path, "?"); ok {
+		path = p
+
+		if len(query) == 0 {
+			rawQuery = q
+		}
+	}
+
+	rUrl := buildFullRequestURL(headers.Get("Host"), path, "", rawQuery)
+	req, err := http.NewRequestWithContext(ctx, method, rUrl, getBody(body, isB64))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header = headers
+
+	if strings.EqualFold(headers.Get("X-Forwarded-Proto"), "http") {
+		req.URL.Scheme = "http"
+	}
+
+	var sourceIP string
+	if xff := headers.Values("X-Forwarded-For"); len(xff) > 0 {
+		parts := strings.Split(xff[len(xff)-1], ",")
+		sourceIP = strings.TrimSpace(parts[len(parts)-1])
+	}
+
+	setHost(req, "")
+	req.TLS = newTLSConnectionState(req, "")
+	req = withSourceIP(req, sourceIP)
+	req.RequestURI = req.URL.RequestURI()
+
+	return req, nil
+}
+
+func convertVPCLatticeV1Request(ctx context.Context, event VPCLatticeV1Request) (*http.Request, error) {
+	q := make(url.Values)
+	for k, v := range event.QueryStringParameters {
+		q.Add(k, v)
+	}
+
+	headers := make(http.Header)
+	for k, v := range event.Headers {
+		headers.Add(k, v)
+	}
+
+	return newVPCLatticeRequest(ctx, event.Method, event.RawPath, q, headers, event.Body, event.IsBase64Encoded)
+}
+
+func convertVPCLatticeV2Request(ctx context.Context, event VPCLatticeV2Request) (*http.Request, error) {
+	q := make(url.Values)
+	for k, values := range event.QueryStringParameters {
+		for _, v := range values {
+			q.Add(k, v)
+		}
+	}
+
+	headers := make(http.Header)
+	for k, values := range event.Headers {
+		for _, v := range values {
+			headers.Add(k, v)
+		}
+	}
+
+	return newVPCLatticeRequest(ctx, event.Method, event.Path, q, headers, event.Body, event.IsBase64Encoded)
+}
+
+func newVPCLatticeResponse(statusCode int, headers http.Header, body []byte, o *options) VPCLatticeResponse {
+	res := VPCLatticeResponse{
+		StatusCode:        statusCode,
+		StatusDescription: strconv.Itoa(statusCode) + " " + http.StatusText(statusCode),
+		Headers:           make(map[string]string),
+	}
+
+	for k, values := range headers {
+		if len(values) == 0 {
+			res.Headers[k] = ""
+		} else if len(values) == 1 {
+			res.Headers[k] = values[0]
+		} else {
+			res.Headers[k] = o.foldHeader(k, values)
+		}
+	}
+
+	res.Body, res.IsBase64Encoded = o.encodeBody(headers, body)
+
+	return res
+}
+
+func handleVPCLattice(ctx context.Context, req *http.Request, adapter AdapterFunc, o *options) (VPCLatticeResponse, error) {
+	o.resolveClientIP(req)
+
+	if err := o.decompressRequest(req); err != nil {
+		return respondError(ctx, o, newVPCLatticeResponse, ErrRequestConversion, err)
+	}
+
+	ctx = req.Context()
+
+	w := newBufferedResponseWriter()
+
+	if err := o.runAdapter(ctx, adapter, req, w); err != nil {
+		return respondError(ctx, o, newVPCLatticeResponse, ErrAdapter, err)
+	}
+
+	statusCode, headers, b, err := w.result(ctx, o)
+	if err != nil {
+		return respondError(ctx, o, newVPCLatticeResponse, ErrResponseBody, err)
+	}
+
+	return finalizeResponse(ctx, o, newVPCLatticeResponse, w.encoding, statusCode, headers, b)
+}
+
+func handleVPCLatticeV1(ctx context.Context, event VPCLatticeV1Request, adapter AdapterFunc, o *options) (VPCLatticeResponse, error) {
+	req, err := convertVPCLatticeV1Request(ctx, event)
+	if err != nil {
+		return respondError(ctx, o, newVPCLatticeResponse, ErrRequestConversion, err)
+	}
+
+	return handleVPCLattice(ctx, req, adapter, o)
+}
+
+func handleVPCLatticeV2(ctx context.Context, event VPCLatticeV2Request, adapter AdapterFunc, o *options) (VPCLatticeResponse, error) {
+	ctx = context.WithValue(ctx, vpcLatticeIdentityContextKey, event.RequestContext.Identity)
+
+	req, err := convertVPCLatticeV2Request(ctx, event)
+	if err != nil {
+		return respondError(ctx, o, newVPCLatticeResponse, ErrRequestConversion, err)
+	}
+
+	return handleVPCLattice(ctx, req, adapter, o)
+}
+
+func NewVPCLatticeV1Handler(adapter AdapterFunc, opts ...Option) func(context.Context, VPCLatticeV1Request) (VPCLatticeResponse, error) {
+	return NewHandler(withOptions(handleVPCLatticeV1, newOptions(opts)), adapter)
+}
+
+func NewVPCLatticeV2Handler(adapter AdapterFunc, opts ...Option) func(context.Context, VPCLatticeV2Request) (VPCLatticeResponse, error) {
+	return NewHandler(withOptions(handleVPCLatticeV2, newOptions(opts)), adapter)
+}
+
+// GetVPCLatticeIdentity returns the identity of the caller for requests received using the VPC Lattice event structure version 2
+func GetVPCLatticeIdentity(ctx context.Context) (VPCLatticeIdentity, bool) {
+	identity, ok := ctx.Value(vpcLatticeIdentityContextKey).(VPCLatticeIdentity)
+	return identity, ok
+}
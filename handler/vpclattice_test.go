@@ -0,0 +1,124 @@
+//go:build !lambdahttpadapter.partial || (lambdahttpadapter.partial && lambdahttpadapter.vpclattice)
+
+package handler
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestVPCLatticeV1(t *testing.T) {
+	var caughtRequest *http.Request
+	var caughtBody []byte
+
+	h := NewVPCLatticeV1Handler(func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+		caughtRequest = r
+		caughtBody, _ = io.ReadAll(r.Body)
+
+		if _, ok := GetVPCLatticeIdentity(ctx); ok {
+			t.Error("expected no identity for event structure version 1")
+		}
+
+		w.Header().Add("X-Example", "a")
+		w.Header().Add("X-Example", "b")
+		_, _ = w.Write([]byte{0xff, 0xfe})
+		return nil
+	})
+
+	res, err := h(context.Background(), VPCLatticeV1Request{
+		RawPath:               "/example",
+		Method:                http.MethodPut,
+		Headers:               map[string]string{"host": "svc.example.com", "x-forwarded-for": "10.0.0.1"},
+		QueryStringParameters: map[string]string{"key": "a b"},
+		Body:                  base64.StdEncoding.EncodeToString([]byte("hello world")),
+		IsBase64Encoded:       true,
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if caughtRequest.URL.String() != "https://svc.example.com/example?key=a+b" {
+		t.Errorf("unexpected url: %v", caughtRequest.URL.String())
+	}
+
+	if caughtRequest.RemoteAddr != "10.0.0.1:0" {
+		t.Errorf("unexpected remote addr: %v", caughtRequest.RemoteAddr)
+	}
+
+	if string(caughtBody) != "hello world" {
+		t.Errorf("unexpected body: %v", string(caughtBody))
+	}
+
+	if res.StatusCode != http.StatusOK || res.StatusDescription != "200 OK" {
+		t.Errorf("unexpected status: %v %v", res.StatusCode, res.StatusDescription)
+	}
+
+	if res.Headers["X-Example"] != "a,b" {
+		t.Error("expected header values to be folded")
+	}
+
+	if !res.IsBase64Encoded || res.Body != base64.StdEncoding.EncodeToString([]byte{0xff, 0xfe}) {
+		t.Error("expected body to be base64 encoded")
+	}
+}
+
+func TestVPCLatticeV2(t *testing.T) {
+	var caughtRequest *http.Request
+	var caughtIdentity VPCLatticeIdentity
+
+	h := NewVPCLatticeV2Handler(func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+		caughtRequest = r
+
+		var ok bool
+		if caughtIdentity, ok = GetVPCLatticeIdentity(r.Context()); !ok {
+			t.Error("expected identity to be present")
+		}
+
+		_, _ = w.Write([]byte("hello world"))
+		return nil
+	})
+
+	identity := VPCLatticeIdentity{
+		SourceVPCARN: "arn:aws:ec2:eu-central-1:123456789012:vpc/vpc-0b8276c84697e7339",
+		Type:         "AWS_IAM",
+		Principal:    "arn:aws:sts::123456789012:assumed-role/example-role/session",
+		SessionName:  "session",
+	}
+
+	res, err := h(context.Background(), VPCLatticeV2Request{
+		Version:               "2.0",
+		Path:                  "/example",
+		Method:                http.MethodGet,
+		Headers:               map[string][]string{"host": {"svc.example.com"}, "accept": {"text/plain", "application/json"}},
+		QueryStringParameters: map[string][]string{"key": {"a", "b"}},
+		RequestContext: VPCLatticeRequestContext{
+			ServiceNetworkARN: "arn:aws:vpc-lattice:eu-central-1:123456789012:servicenetwork/sn-0bf3f2882e9cc805a",
+			Identity:          identity,
+		},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if caughtIdentity != identity {
+		t.Errorf("unexpected identity: %v", caughtIdentity)
+	}
+
+	if !reflect.DeepEqual(caughtRequest.URL.Query()["key"], []string{"a", "b"}) {
+		t.Errorf("unexpected query: %v", caughtRequest.URL.RawQuery)
+	}
+
+	if !reflect.DeepEqual(caughtRequest.Header.Values("Accept"), []string{"text/plain", "application/json"}) {
+		t.Error("expected all header values to be present")
+	}
+
+	if res.Body != "hello world" || res.IsBase64Encoded {
+		t.Error("unexpected body")
+	}
+}
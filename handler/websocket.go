@@ -0,0 +1,199 @@
+//go:build !lambdahttpadapter.partial || (lambdahttpadapter.partial && lambdahttpadapter.websocket)
+
+package handler
+
+import (
+	"context"
+	"errors"
+	"github.com/aws/aws-lambda-go/events"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+const (
+	HeaderWebsocketConnectionID = "X-Websocket-Connection-Id"
+	HeaderWebsocketRouteKey     = "X-Websocket-Route-Key"
+	HeaderWebsocketEventType    = "X-Websocket-Event-Type"
+	HeaderWebsocketMessageID    = "X-Websocket-Message-Id"
+)
+
+var connectionPosterContextKey httpContextKey = "github.com/its-felix/aws-lambda-go-http-adapter/handler/websocket::connectionPosterContextKey"
+
+var ErrConnectionGone = errors.New("connection is gone")
+
+func init() {
+	registerAutoHandler(eventFormatWebsocket, func(ctx context.Context, event events.APIGatewayWebsocketProxyRequest, adapter AdapterFunc, o *options) (events.APIGatewayProxyResponse, error) {
+		return handleWebsocket(ctx, event, adapter, o, nil)
+	}, newWebsocketResponse)
+}
+
+// ConnectionPoster sends messages to clients connected to an API Gateway WebSocket API.
+// An implementation based on the API Gateway Management API is expected to return ErrConnectionGone if the client is no longer connected.
+type ConnectionPoster interface {
+	PostToConnection(ctx context.Context, connectionID string, data []byte) error
+}
+
+// InMemoryConnectionPoster is a ConnectionPoster which keeps all messages in memory, meant to be used in tests
+type InMemoryConnectionPoster struct {
+	mu           sync.Mutex
+	messages     map[string][][]byte
+	disconnected map[string]bool
+}
+
+func NewInMemoryConnectionPoster() *InMemoryConnectionPoster {
+	return &InMemoryConnectionPoster{
+		messages:     make(map[string][][]byte),
+		disconnected: make(map[string]bool),
+	}
+}
+
+func (p *InMemoryConnectionPoster) PostToConnection(ctx context.Context, connectionID string, data []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.disconnected[connectionID] {
+		return ErrConnectionGone
+	}
+
+	p.messages[connectionID] = append(p.messages[connectionID], append([]byte(nil), data...))
+	return nil
+}
+
+// Messages returns all messages posted to the given connection
+func (p *InMemoryConnectionPoster) Messages(connectionID string) [][]byte {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([][]byte(nil), p.messages[connectionID]...)
+}
+
+// Disconnect marks the connection as gone, further messages posted to it will fail with ErrConnectionGone
+func (p *InMemoryConnectionPoster) Disconnect(connectionID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.disconnected[connectionID] = true
+}
+
+func convertWebsocketRequest(ctx context.Context, event events.APIGatewayWebsocketProxyRequest) (*http.Request, error) {
+	q := make(url.Values)
+
+	if len(event.MultiValueQueryStringParameters) > 0 {
+		for k, values := range event.MultiValueQueryStringParameters {
+			for _, v := range values {
+				q.Add(k, v)
+			}
+		}
+	} else if len(event.QueryStringParameters) > 0 {
+		for k, v := range event.QueryStringParameters {
+			q.Add(k, v)
+		}
+	}
+
+	// every route is mapped onto a POST request to /{routeKey}, for example POST /$connect.
+	// Custom route keys are taken from the message body and may contain any character, so they're escaped.
+	routeKey := url.PathEscape(event.RequestContext.RouteKey)
+	rUrl := buildFullRequestURL(event.RequestContext.DomainName, routeKey, "", q.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rUrl, getBody(event.Body, event.IsBase64Encoded))
+	if err != nil {
+		return nil, err
+	}
+
+	req.URL.Path = "/" + event.RequestContext.RouteKey
+	req.URL.RawPath = "/" + routeKey
+
+	if event.MultiValueHeaders != nil {
+		for k, values := range event.MultiValueHeaders {
+			for _, v := range values {
+				req.Header.Add(k, v)
+			}
+		}
+	} else {
+		for k, v := range event.Headers {
+			req.Header.Add(k, v)
+		}
+	}
+
+	req.Header.Set(HeaderWebsocketConnectionID, event.RequestContext.ConnectionID)
+	req.Header.Set(HeaderWebsocketRouteKey, event.RequestContext.RouteKey)
+	req.Header.Set(HeaderWebsocketEventType, event.RequestContext.EventType)
+
+	if messageID, ok := event.RequestContext.MessageID.(string); ok && messageID != "" {
+		req.Header.Set(HeaderWebsocketMessageID, messageID)
+	}
+
+	setHost(req, event.RequestContext.DomainName)
+	req.TLS = newTLSConnectionState(req, "")
+	req = withSourceIP(req, event.RequestContext.Identity.SourceIP)
+	req.RequestURI = req.URL.RequestURI()
+
+	return req, nil
+}
+
+func newWebsocketResponse(statusCode int, headers http.Header, body []byte, o *options) events.APIGatewayProxyResponse {
+	res := events.APIGatewayProxyResponse{
+		StatusCode: statusCode,
+		Headers:    make(map[string]string),
+	}
+
+	for k, values := range headers {
+		if len(values) == 0 {
+			res.Headers[k] = ""
+		} else if len(values) == 1 {
+			res.Headers[k] = values[0]
+		} else {
+			res.Headers[k] = o.foldHeader(k, values)
+		}
+	}
+
+	res.Body, res.IsBase64Encoded = o.encodeBody(headers, body)
+
+	return res
+}
+
+func handleWebsocket(ctx context.Context, event events.APIGatewayWebsocketProxyRequest, adapter AdapterFunc, o *options, poster ConnectionPoster) (events.APIGatewayProxyResponse, error) {
+	if poster != nil {
+		ctx = context.WithValue(ctx, connectionPosterContextKey, poster)
+	}
+
+	req, err := convertWebsocketRequest(ctx, event)
+	if err != nil {
+		return respondError(ctx, o, newWebsocketResponse, ErrRequestConversion, err)
+	}
+
+	o.resolveClientIP(req)
+
+	if err = o.decompressRequest(req); err != nil {
+		return respondError(ctx, o, newWebsocketResponse, ErrRequestConversion, err)
+	}
+
+	ctx = req.Context()
+
+	w := newBufferedResponseWriter()
+
+	if err = o.runAdapter(ctx, adapter, req, w); err != nil {
+		return respondError(ctx, o, newWebsocketResponse, ErrAdapter, err)
+	}
+
+	statusCode, headers, b, err := w.result(ctx, o)
+	if err != nil {
+		return respondError(ctx, o, newWebsocketResponse, ErrResponseBody, err)
+	}
+
+	return finalizeResponse(ctx, o, newWebsocketResponse, w.encoding, statusCode, headers, b)
+}
+
+// NewWebsocketHandler creates a handler for API Gateway WebSocket APIs.
+// Every route is mapped onto a POST request to /{routeKey} (for example POST /$connect), the connection ID is available in the X-Websocket-Connection-Id header.
+// The poster is made available to the adapter using GetConnectionPoster and may be nil.
+func NewWebsocketHandler(adapter AdapterFunc, poster ConnectionPoster, opts ...Option) func(context.Context, events.APIGatewayWebsocketProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return NewHandler(withOptions(func(ctx context.Context, event events.APIGatewayWebsocketProxyRequest, adapter AdapterFunc, o *options) (events.APIGatewayProxyResponse, error) {
+		return handleWebsocket(ctx, event, adapter, o, poster)
+	}, newOptions(opts)), adapter)
+}
+
+func GetConnectionPoster(ctx context.Context) (ConnectionPoster, bool) {
+	poster, ok := ctx.Value(connectionPosterContextKey).(ConnectionPoster)
+	return poster, ok
+}
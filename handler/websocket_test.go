@@ -0,0 +1,123 @@
+//go:build !lambdahttpadapter.partial || (lambdahttpadapter.partial && lambdahttpadapter.websocket)
+
+package handler
+
+import (
+	"context"
+	"errors"
+	"github.com/aws/aws-lambda-go/events"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func newWebsocketEvent(routeKey, eventType, body string) events.APIGatewayWebsocketProxyRequest {
+	return events.APIGatewayWebsocketProxyRequest{
+		Body: body,
+		RequestContext: events.APIGatewayWebsocketProxyRequestContext{
+			ConnectionID: "L0SM9cOFvHcCIhw=",
+			DomainName:   "id.execute-api.eu-central-1.amazonaws.com",
+			EventType:    eventType,
+			RouteKey:     routeKey,
+			Stage:        "prod",
+			Identity: events.APIGatewayRequestIdentity{
+				SourceIP: "127.0.0.1",
+			},
+		},
+	}
+}
+
+func TestWebsocket(t *testing.T) {
+	poster := NewInMemoryConnectionPoster()
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/$connect", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("unexpected method: %v", r.Method)
+		}
+
+		if r.URL.Query().Get("token") != "abc" {
+			t.Error("expected query parameters to be present")
+		}
+
+		if r.Header.Get(HeaderWebsocketEventType) != "CONNECT" {
+			t.Errorf("unexpected event type: %v", r.Header.Get(HeaderWebsocketEventType))
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+
+	mux.HandleFunc("/sendMessage", func(w http.ResponseWriter, r *http.Request) {
+		p, ok := GetConnectionPoster(r.Context())
+		if !ok {
+			t.Fatal("expected the connection poster to be present")
+		}
+
+		b, _ := io.ReadAll(r.Body)
+		if err := p.PostToConnection(r.Context(), r.Header.Get(HeaderWebsocketConnectionID), b); err != nil {
+			t.Error(err)
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+
+	h := NewWebsocketHandler(func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+		mux.ServeHTTP(w, r)
+		return nil
+	}, poster)
+
+	event := newWebsocketEvent("$connect", "CONNECT", "")
+	event.QueryStringParameters = map[string]string{"token": "abc"}
+
+	res, err := h(context.Background(), event)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("unexpected status: %v", res.StatusCode)
+	}
+
+	res, err = h(context.Background(), newWebsocketEvent("sendMessage", "MESSAGE", "hello world"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("unexpected status: %v", res.StatusCode)
+	}
+
+	if !reflect.DeepEqual(poster.Messages("L0SM9cOFvHcCIhw="), [][]byte{[]byte("hello world")}) {
+		t.Errorf("unexpected messages: %v", poster.Messages("L0SM9cOFvHcCIhw="))
+	}
+}
+
+func TestWebsocketRouteKeyEscaping(t *testing.T) {
+	var req *http.Request
+	h := NewWebsocketHandler(func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+		req = r
+		return nil
+	}, nil)
+
+	if _, err := h(context.Background(), newWebsocketEvent("chat#1?a/100%", "MESSAGE", "")); err != nil {
+		t.Fatal(err)
+	}
+
+	if req.URL.Path != "/chat#1?a/100%" || req.URL.RawQuery != "" {
+		t.Errorf("unexpected path %q and query %q", req.URL.Path, req.URL.RawQuery)
+	}
+
+	if req.RequestURI != "/chat%231%3Fa%2F100%25" {
+		t.Errorf("unexpected request URI: %q", req.RequestURI)
+	}
+}
+
+func TestInMemoryConnectionPosterDisconnect(t *testing.T) {
+	poster := NewInMemoryConnectionPoster()
+	poster.Disconnect("abc")
+
+	if err := poster.PostToConnection(context.Background(), "abc", []byte("hello world")); !errors.Is(err, ErrConnectionGone) {
+		t.Errorf("expected ErrConnectionGone, got %v", err)
+	}
+}
@@ -0,0 +1,205 @@
+// Package handlertest provides builders for Lambda events and a uniform view of the responses returned by the handlers,
+// so that routes can be tested through the real handlers.
+package handlertest
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/its-felix/aws-lambda-go-http-adapter/local"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+)
+
+// Builder builds an event of type T from a fluent description of a HTTP request
+type Builder[T any] struct {
+	method   string
+	path     string
+	query    url.Values
+	header   http.Header
+	body     []byte
+	host     string
+	sourceIP string
+	convert  func(r *http.Request) (T, error)
+	modify   []func(event *T)
+	err      error
+}
+
+func newBuilder[T any](host string, convert func(r *http.Request) (T, error)) *Builder[T] {
+	return &Builder[T]{
+		method:   http.MethodGet,
+		path:     "/",
+		query:    make(url.Values),
+		header:   make(http.Header),
+		host:     host,
+		sourceIP: "127.0.0.1",
+		convert:  convert,
+	}
+}
+
+// FunctionURL creates a builder for events handled by handler.NewFunctionURLHandler and handler.NewFunctionURLStreamingHandler
+func FunctionURL() *Builder[events.LambdaFunctionURLRequest] {
+	return newBuilder("0dhg9709da0dhg9709da0dhg9709da.lambda-url.eu-central-1.on.aws", local.FunctionURLFormat.NewEvent)
+}
+
+// APIGatewayV1 creates a builder for events handled by handler.NewAPIGatewayV1Handler
+func APIGatewayV1() *Builder[events.APIGatewayProxyRequest] {
+	return newBuilder("0dhg9709da.execute-api.eu-central-1.amazonaws.com", local.APIGatewayV1Format.NewEvent)
+}
+
+// APIGatewayV2 creates a builder for events handled by handler.NewAPIGatewayV2Handler
+func APIGatewayV2() *Builder[events.APIGatewayV2HTTPRequest] {
+	return newBuilder("0dhg9709da.execute-api.eu-central-1.amazonaws.com", local.APIGatewayV2Format.NewEvent)
+}
+
+// ALB creates a builder for events handled by handler.NewALBHandler with multi value headers disabled on the target group
+func ALB() *Builder[events.ALBTargetGroupRequest] {
+	return newBuilder("example.com", func(r *http.Request) (events.ALBTargetGroupRequest, error) {
+		return newALBRequest(r, false)
+	})
+}
+
+// ALBMultiValue creates a builder for events handled by handler.NewALBHandler with multi value headers enabled on the target group
+func ALBMultiValue() *Builder[events.ALBTargetGroupRequest] {
+	return newBuilder("example.com", func(r *http.Request) (events.ALBTargetGroupRequest, error) {
+		return newALBRequest(r, true)
+	})
+}
+
+// Method sets the method and path of the request, the path may contain a query string
+func (b *Builder[T]) Method(method, path string) *Builder[T] {
+	b.method = method
+
+	u, err := url.Parse(path)
+	if err != nil {
+		b.err = err
+		return b
+	}
+
+	b.path = u.EscapedPath()
+	for k, values := range u.Query() {
+		b.query[k] = append(b.query[k], values...)
+	}
+
+	return b
+}
+
+func (b *Builder[T]) GET(path string) *Builder[T] {
+	return b.Method(http.MethodGet, path)
+}
+
+func (b *Builder[T]) HEAD(path string) *Builder[T] {
+	return b.Method(http.MethodHead, path)
+}
+
+func (b *Builder[T]) POST(path string) *Builder[T] {
+	return b.Method(http.MethodPost, path)
+}
+
+func (b *Builder[T]) PUT(path string) *Builder[T] {
+	return b.Method(http.MethodPut, path)
+}
+
+func (b *Builder[T]) PATCH(path string) *Builder[T] {
+	return b.Method(http.MethodPatch, path)
+}
+
+func (b *Builder[T]) DELETE(path string) *Builder[T] {
+	return b.Method(http.MethodDelete, path)
+}
+
+func (b *Builder[T]) OPTIONS(path string) *Builder[T] {
+	return b.Method(http.MethodOptions, path)
+}
+
+// Query adds a query parameter
+func (b *Builder[T]) Query(key, value string) *Builder[T] {
+	b.query.Add(key, value)
+	return b
+}
+
+// Header adds a header
+func (b *Builder[T]) Header(key, value string) *Builder[T] {
+	b.header.Add(key, value)
+	return b
+}
+
+// Cookie adds a cookie to the Cookie header
+func (b *Builder[T]) Cookie(name, value string) *Builder[T] {
+	b.header.Add("Cookie", (&http.Cookie{Name: name, Value: value}).String())
+	return b
+}
+
+// Body sets the body, binary bodies are base64-encoded in the event
+func (b *Builder[T]) Body(body []byte) *Builder[T] {
+	b.body = body
+	return b
+}
+
+// Text sets the body and the Content-Type header to text/plain
+func (b *Builder[T]) Text(body string) *Builder[T] {
+	b.header.Set("Content-Type", "text/plain; charset=utf-8")
+	return b.Body([]byte(body))
+}
+
+// JSON sets the body to the JSON encoding of v and the Content-Type header to application/json
+func (b *Builder[T]) JSON(v any) *Builder[T] {
+	body, err := json.Marshal(v)
+	if err != nil {
+		b.err = err
+		return b
+	}
+
+	b.header.Set("Content-Type", "application/json")
+	return b.Body(body)
+}
+
+// Host sets the host (and domain name) of the request
+func (b *Builder[T]) Host(host string) *Builder[T] {
+	b.host = host
+	return b
+}
+
+// SourceIP sets the IP address of the client
+func (b *Builder[T]) SourceIP(ip string) *Builder[T] {
+	b.sourceIP = ip
+	return b
+}
+
+// Modify registers a function which is called with the built event, allowing to set fields specific to the event format
+func (b *Builder[T]) Modify(fn func(event *T)) *Builder[T] {
+	b.modify = append(b.modify, fn)
+	return b
+}
+
+// Build builds the event. It panics if the event can't be built, for example because JSON failed to encode the body.
+func (b *Builder[T]) Build() T {
+	if b.err != nil {
+		panic(b.err)
+	}
+
+	target := b.path
+	if len(b.query) > 0 {
+		target += "?" + b.query.Encode()
+	}
+
+	r := httptest.NewRequest(b.method, target, bytes.NewReader(b.body))
+	r.Host = b.host
+	r.RemoteAddr = b.sourceIP + ":1234"
+
+	for k, values := range b.header {
+		r.Header[k] = append([]string(nil), values...)
+	}
+
+	event, err := b.convert(r)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, fn := range b.modify {
+		fn(&event)
+	}
+
+	return event
+}
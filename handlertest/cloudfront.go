@@ -0,0 +1,108 @@
+//go:build !lambdahttpadapter.partial || (lambdahttpadapter.partial && lambdahttpadapter.cloudfront)
+
+package handlertest
+
+import (
+	"encoding/base64"
+	"fmt"
+	"github.com/its-felix/aws-lambda-go-http-adapter/handler"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	responseConverters = append(responseConverters, func(res any) (Response, bool, error) {
+		switch res := res.(type) {
+		case handler.CloudFrontResult:
+			r, err := newCloudFrontResultResponse(res)
+			return r, true, err
+		case handler.CloudFrontResponse:
+			r, err := newCloudFrontResponse(res)
+			return r, true, err
+		}
+
+		return Response{}, false, nil
+	})
+}
+
+func newCloudFrontResultResponse(res handler.CloudFrontResult) (Response, error) {
+	if res.Response == nil {
+		return Response{}, fmt.Errorf("the request was passed through to the origin")
+	}
+
+	return newCloudFrontResponse(*res.Response)
+}
+
+func newCloudFrontResponse(res handler.CloudFrontResponse) (Response, error) {
+	statusCode, err := strconv.Atoi(res.Status)
+	if err != nil {
+		return Response{}, err
+	}
+
+	multiValueHeaders := make(map[string][]string)
+	for _, values := range res.Headers {
+		for _, v := range values {
+			multiValueHeaders[v.Key] = append(multiValueHeaders[v.Key], v.Value)
+		}
+	}
+
+	return newResponse(statusCode, nil, multiValueHeaders, nil, res.Body, res.BodyEncoding == "base64")
+}
+
+// CloudFront creates a builder for events handled by handler.NewCloudFrontHandler, eventType is one of handler.CloudFrontEventTypeViewerRequest or handler.CloudFrontEventTypeOriginRequest
+func CloudFront(eventType string) *Builder[handler.CloudFrontEvent] {
+	return newBuilder("d111111abcdef8.cloudfront.net", func(r *http.Request) (handler.CloudFrontEvent, error) {
+		return newCloudFrontEvent(r, eventType)
+	})
+}
+
+func newCloudFrontEvent(r *http.Request, eventType string) (handler.CloudFrontEvent, error) {
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return handler.CloudFrontEvent{}, err
+	}
+
+	cfReq := handler.CloudFrontRequest{
+		ClientIP:    sourceIP(r),
+		Method:      r.Method,
+		URI:         r.URL.EscapedPath(),
+		QueryString: r.URL.RawQuery,
+		Headers:     make(handler.CloudFrontHeaders),
+	}
+
+	headers := r.Header.Clone()
+	headers.Set("Host", r.Host)
+
+	for k, values := range headers {
+		lk := strings.ToLower(k)
+		for _, v := range values {
+			cfReq.Headers[lk] = append(cfReq.Headers[lk], handler.CloudFrontHeader{Key: k, Value: v})
+		}
+	}
+
+	if len(b) > 0 {
+		cfReq.Body = &handler.CloudFrontRequestBody{
+			Action:   "read-only",
+			Data:     base64.StdEncoding.EncodeToString(b),
+			Encoding: "base64",
+		}
+	}
+
+	return handler.CloudFrontEvent{
+		Records: []handler.CloudFrontEventRecord{
+			{
+				CF: handler.CloudFrontRecord{
+					Config: handler.CloudFrontConfig{
+						DistributionDomainName: "d111111abcdef8.cloudfront.net",
+						DistributionID:         "EDFDVBD6EXAMPLE",
+						EventType:              eventType,
+						RequestID:              "4TyzHTaYWb1GX1qTfsHhEqV6HUDd_BzoBZnwfnvQc_1oF26ClkoUSEQ==",
+					},
+					Request: cfReq,
+				},
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,100 @@
+package handlertest
+
+import (
+	"encoding/base64"
+	"github.com/aws/aws-lambda-go/events"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+func readBody(r *http.Request) (string, bool, error) {
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return "", false, err
+	}
+
+	if len(b) == 0 {
+		return "", false, nil
+	} else if utf8.Valid(b) {
+		return string(b), false, nil
+	}
+
+	return base64.StdEncoding.EncodeToString(b), true, nil
+}
+
+func sourceIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// forwardedHeaders returns the request headers including those added by load balancers
+func forwardedHeaders(r *http.Request) http.Header {
+	headers := r.Header.Clone()
+	headers.Set("Host", r.Host)
+	headers.Set("X-Forwarded-For", sourceIP(r))
+	headers.Set("X-Forwarded-Proto", "https")
+	headers.Set("X-Forwarded-Port", "443")
+
+	return headers
+}
+
+func newALBRequest(r *http.Request, multiValue bool) (events.ALBTargetGroupRequest, error) {
+	body, isB64, err := readBody(r)
+	if err != nil {
+		return events.ALBTargetGroupRequest{}, err
+	}
+
+	event := events.ALBTargetGroupRequest{
+		HTTPMethod: r.Method,
+		Path:       r.URL.Path,
+		RequestContext: events.ALBTargetGroupRequestContext{
+			ELB: events.ELBContext{
+				TargetGroupArn: "arn:aws:elasticloadbalancing:eu-central-1:123456789012:targetgroup/example/0dhg9709da",
+			},
+		},
+		Body:            body,
+		IsBase64Encoded: isB64,
+	}
+
+	// ALB passes the query parameters exactly as they were sent by the client
+	query := make(map[string][]string)
+	for _, part := range strings.Split(r.URL.RawQuery, "&") {
+		if part == "" {
+			continue
+		}
+
+		k, v, _ := strings.Cut(part, "=")
+		query[k] = append(query[k], v)
+	}
+
+	headers := forwardedHeaders(r)
+
+	if multiValue {
+		event.MultiValueHeaders = make(map[string][]string)
+		event.MultiValueQueryStringParameters = query
+
+		for k, values := range headers {
+			event.MultiValueHeaders[strings.ToLower(k)] = values
+		}
+	} else {
+		event.Headers = make(map[string]string)
+		event.QueryStringParameters = make(map[string]string)
+
+		for k, values := range query {
+			event.QueryStringParameters[k] = values[len(values)-1]
+		}
+
+		for k, values := range headers {
+			event.Headers[strings.ToLower(k)] = values[len(values)-1]
+		}
+	}
+
+	return event, nil
+}
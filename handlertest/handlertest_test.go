@@ -0,0 +1,169 @@
+//go:build !lambdahttpadapter.partial || (lambdahttpadapter.partial && lambdahttpadapter.alb && lambdahttpadapter.apigwv1 && lambdahttpadapter.apigwv2 && lambdahttpadapter.functionurl && lambdahttpadapter.vpclattice && lambdahttpadapter.cloudfront && lambdahttpadapter.websocket && lambdahttpadapter.vanilla)
+
+package handlertest
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/its-felix/aws-lambda-go-http-adapter/adapter"
+	"github.com/its-felix/aws-lambda-go-http-adapter/handler"
+	"io"
+	"net/http"
+	"testing"
+)
+
+type echoResult struct {
+	Method     string
+	URL        string
+	Body       string
+	Cookie     string
+	RemoteAddr string
+}
+
+func newEchoAdapter() handler.AdapterFunc {
+	return adapter.NewVanillaAdapter(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		c, _ := r.Cookie("session")
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Example", "value")
+		http.SetCookie(w, &http.Cookie{Name: "example", Value: "value"})
+		w.WriteHeader(http.StatusCreated)
+
+		_ = json.NewEncoder(w).Encode(echoResult{
+			Method:     r.Method,
+			URL:        r.URL.Path + "?" + r.URL.RawQuery,
+			Body:       string(b),
+			Cookie:     c.String(),
+			RemoteAddr: r.RemoteAddr,
+		})
+	}))
+}
+
+func TestBuilders(t *testing.T) {
+	a := newEchoAdapter()
+	body := map[string]string{"hello": "world"}
+
+	tests := map[string]func() (any, error){
+		"FunctionURL": func() (any, error) {
+			return handler.NewFunctionURLHandler(a)(context.Background(), FunctionURL().POST("/example?key=value").JSON(body).Cookie("session", "abc").Build())
+		},
+		"FunctionURLStreaming": func() (any, error) {
+			return handler.NewFunctionURLStreamingHandler(a)(context.Background(), FunctionURL().POST("/example?key=value").JSON(body).Cookie("session", "abc").Build())
+		},
+		"APIGatewayV1": func() (any, error) {
+			return handler.NewAPIGatewayV1Handler(a)(context.Background(), APIGatewayV1().POST("/example?key=value").JSON(body).Cookie("session", "abc").Build())
+		},
+		"APIGatewayV2": func() (any, error) {
+			return handler.NewAPIGatewayV2Handler(a)(context.Background(), APIGatewayV2().POST("/example?key=value").JSON(body).Cookie("session", "abc").Build())
+		},
+		"ALB": func() (any, error) {
+			return handler.NewALBHandler(a)(context.Background(), ALB().POST("/example?key=value").JSON(body).Cookie("session", "abc").Build())
+		},
+		"ALBMultiValue": func() (any, error) {
+			return handler.NewALBHandler(a)(context.Background(), ALBMultiValue().POST("/example?key=value").JSON(body).Cookie("session", "abc").Build())
+		},
+		"VPCLatticeV1": func() (any, error) {
+			return handler.NewVPCLatticeV1Handler(a)(context.Background(), VPCLatticeV1().POST("/example?key=value").JSON(body).Cookie("session", "abc").Build())
+		},
+		"VPCLatticeV2": func() (any, error) {
+			return handler.NewVPCLatticeV2Handler(a)(context.Background(), VPCLatticeV2().POST("/example?key=value").JSON(body).Cookie("session", "abc").Build())
+		},
+		"CloudFront": func() (any, error) {
+			return handler.NewCloudFrontHandler(a)(context.Background(), CloudFront(handler.CloudFrontEventTypeOriginRequest).POST("/example?key=value").JSON(body).Cookie("session", "abc").Build())
+		},
+		"Websocket": func() (any, error) {
+			return handler.NewWebsocketHandler(a, nil)(context.Background(), Websocket().POST("/example?key=value").JSON(body).Cookie("session", "abc").Build())
+		},
+	}
+
+	for name, invoke := range tests {
+		t.Run(name, func(t *testing.T) {
+			res, err := invoke()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r := MustNewResponse(t, res)
+			r.AssertStatus(t, http.StatusCreated)
+			r.AssertHeader(t, "X-Example", "value")
+
+			if c := r.Cookie("example"); c == nil || c.Value != "value" {
+				t.Errorf("expected the cookie to be present, got %v", r.Cookies())
+			}
+
+			var result echoResult
+			if err = r.JSON(&result); err != nil {
+				t.Fatal(err)
+			}
+
+			expected := echoResult{
+				Method:     http.MethodPost,
+				URL:        "/example?key=value",
+				Body:       `{"hello":"world"}`,
+				Cookie:     "session=abc",
+				RemoteAddr: "127.0.0.1:0",
+			}
+
+			if result != expected {
+				t.Errorf("unexpected request: %+v", result)
+			}
+		})
+	}
+}
+
+func TestBuilderModify(t *testing.T) {
+	event := Websocket().
+		POST("/$connect").
+		Header(handler.HeaderWebsocketConnectionID, "abc").
+		Modify(func(event *events.APIGatewayWebsocketProxyRequest) {
+			event.RequestContext.Stage = "dev"
+		}).
+		Build()
+
+	if event.RequestContext.RouteKey != "$connect" || event.RequestContext.EventType != "CONNECT" || event.RequestContext.ConnectionID != "abc" {
+		t.Errorf("unexpected request context: %+v", event.RequestContext)
+	}
+
+	if event.RequestContext.Stage != "dev" {
+		t.Error("expected the modification to be applied")
+	}
+}
+
+func TestBinaryBody(t *testing.T) {
+	event := FunctionURL().POST("/").Body([]byte{0xff, 0x00}).Build()
+	if !event.IsBase64Encoded || event.Body != "/wA=" {
+		t.Errorf("expected the body to be base64-encoded, got %q", event.Body)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	store := &MemoryStore{}
+	body := make([]byte, 2048)
+
+	h := handler.NewFunctionURLHandler(adapter.NewVanillaAdapter(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc"})
+		_, _ = w.Write(body)
+	})), handler.WithMaxResponseSize(1024), handler.WithLargeResponseStore(store, 0))
+
+	r, err := h(context.Background(), FunctionURL().GET("/report").Build())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	redirect := MustNewResponse(t, r)
+	redirect.AssertStatus(t, http.StatusSeeOther)
+
+	if redirect.Cookie("session") == nil {
+		t.Error("expected the cookies to be kept")
+	}
+
+	stored, ok := store.Get(redirect.Header.Get("Location"))
+	if !ok || len(stored.Body) != len(body) {
+		t.Fatalf("expected the body to be stored at %q", redirect.Header.Get("Location"))
+	}
+
+	stored.AssertHeader(t, "Content-Type", "application/octet-stream")
+}
@@ -0,0 +1,164 @@
+package handlertest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/aws/aws-lambda-go/events"
+	"io"
+	"net/http"
+	"testing"
+)
+
+// Response is a uniform view of the responses returned by the handlers
+type Response struct {
+	StatusCode int
+	// Header contains all headers of the response, including cookies as Set-Cookie
+	Header http.Header
+	// Body is the decoded body of the response
+	Body []byte
+}
+
+// responseConverters is populated by the event formats which are only part of the build if enabled using build tags
+var responseConverters = make([]func(res any) (Response, bool, error), 0)
+
+// NewResponse creates a Response from any response returned by the handlers of this library.
+// Streaming responses are read until the end of the body.
+func NewResponse(res any) (Response, error) {
+	switch res := res.(type) {
+	case events.APIGatewayProxyResponse:
+		return newResponse(res.StatusCode, res.Headers, res.MultiValueHeaders, nil, res.Body, res.IsBase64Encoded)
+	case events.APIGatewayV2HTTPResponse:
+		return newResponse(res.StatusCode, res.Headers, res.MultiValueHeaders, res.Cookies, res.Body, res.IsBase64Encoded)
+	case events.LambdaFunctionURLResponse:
+		return newResponse(res.StatusCode, res.Headers, nil, res.Cookies, res.Body, res.IsBase64Encoded)
+	case events.ALBTargetGroupResponse:
+		return newResponse(res.StatusCode, res.Headers, res.MultiValueHeaders, nil, res.Body, res.IsBase64Encoded)
+	case *events.LambdaFunctionURLStreamingResponse:
+		if res == nil {
+			return Response{}, fmt.Errorf("nil response")
+		}
+
+		r, err := newResponse(res.StatusCode, res.Headers, nil, res.Cookies, "", false)
+		if err != nil {
+			return r, err
+		}
+
+		if res.Body != nil {
+			r.Body, err = io.ReadAll(res.Body)
+		}
+
+		return r, err
+	case events.LambdaFunctionURLStreamingResponse:
+		return NewResponse(&res)
+	}
+
+	for _, convert := range responseConverters {
+		if r, ok, err := convert(res); ok {
+			return r, err
+		}
+	}
+
+	return Response{}, fmt.Errorf("unsupported response type %T", res)
+}
+
+// MustNewResponse is like NewResponse, but fails the test if the Response can't be created
+func MustNewResponse(t testing.TB, res any) Response {
+	t.Helper()
+
+	r, err := NewResponse(res)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return r
+}
+
+func newResponse(statusCode int, headers map[string]string, multiValueHeaders map[string][]string, cookies []string, body string, isB64 bool) (Response, error) {
+	r := Response{
+		StatusCode: statusCode,
+		Header:     make(http.Header),
+	}
+
+	for k, v := range headers {
+		r.Header.Set(k, v)
+	}
+
+	for k, values := range multiValueHeaders {
+		r.Header.Del(k)
+
+		for _, v := range values {
+			r.Header.Add(k, v)
+		}
+	}
+
+	for _, v := range cookies {
+		r.Header.Add("Set-Cookie", v)
+	}
+
+	if isB64 {
+		b, err := base64.StdEncoding.DecodeString(body)
+		if err != nil {
+			return r, err
+		}
+
+		r.Body = b
+	} else {
+		r.Body = []byte(body)
+	}
+
+	return r, nil
+}
+
+// Cookies parses the Set-Cookie headers of the response
+func (r Response) Cookies() []*http.Cookie {
+	return (&http.Response{Header: r.Header}).Cookies()
+}
+
+// Cookie returns the cookie with the given name or nil if it wasn't set
+func (r Response) Cookie(name string) *http.Cookie {
+	for _, c := range r.Cookies() {
+		if c.Name == name {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// Text returns the body as string
+func (r Response) Text() string {
+	return string(r.Body)
+}
+
+// JSON decodes the body into v
+func (r Response) JSON(v any) error {
+	return json.Unmarshal(r.Body, v)
+}
+
+// AssertStatus reports an error if the status code doesn't match
+func (r Response) AssertStatus(t testing.TB, expected int) {
+	t.Helper()
+
+	if r.StatusCode != expected {
+		t.Errorf("expected status %d, got %d", expected, r.StatusCode)
+	}
+}
+
+// AssertHeader reports an error if the first value of the header doesn't match
+func (r Response) AssertHeader(t testing.TB, key, expected string) {
+	t.Helper()
+
+	if actual := r.Header.Get(key); actual != expected {
+		t.Errorf("expected header %s to be %q, got %q", key, expected, actual)
+	}
+}
+
+// AssertBody reports an error if the body doesn't match
+func (r Response) AssertBody(t testing.TB, expected string) {
+	t.Helper()
+
+	if actual := r.Text(); actual != expected {
+		t.Errorf("expected body %q, got %q", expected, actual)
+	}
+}
@@ -0,0 +1,57 @@
+package handlertest
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// MemoryStore is a handler.LargeResponseStore which keeps the bodies in memory, so that offloaded responses can be tested without AWS
+type MemoryStore struct {
+	// BaseURL is the prefix of the returned URLs, https://store.example.com if empty
+	BaseURL string
+
+	mu      sync.Mutex
+	objects map[string]Response
+}
+
+func (s *MemoryStore) Put(ctx context.Context, headers http.Header, body []byte) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.objects == nil {
+		s.objects = make(map[string]Response)
+	}
+
+	baseURL := s.BaseURL
+	if baseURL == "" {
+		baseURL = "https://store.example.com"
+	}
+
+	u := baseURL + "/" + strconv.Itoa(len(s.objects)+1)
+	s.objects[u] = Response{
+		StatusCode: http.StatusOK,
+		Header:     headers.Clone(),
+		Body:       append([]byte(nil), body...),
+	}
+
+	return u, nil
+}
+
+// Get returns the response stored for the URL returned by Put
+func (s *MemoryStore) Get(u string) (Response, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.objects[u]
+	return r, ok
+}
+
+// Len returns the number of stored responses
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.objects)
+}
@@ -0,0 +1,96 @@
+//go:build !lambdahttpadapter.partial || (lambdahttpadapter.partial && lambdahttpadapter.vpclattice)
+
+package handlertest
+
+import (
+	"github.com/its-felix/aws-lambda-go-http-adapter/handler"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func init() {
+	responseConverters = append(responseConverters, func(res any) (Response, bool, error) {
+		if res, ok := res.(handler.VPCLatticeResponse); ok {
+			r, err := newResponse(res.StatusCode, res.Headers, nil, nil, res.Body, res.IsBase64Encoded)
+			return r, true, err
+		}
+
+		return Response{}, false, nil
+	})
+}
+
+// VPCLatticeV1 creates a builder for events handled by handler.NewVPCLatticeV1Handler
+func VPCLatticeV1() *Builder[handler.VPCLatticeV1Request] {
+	return newBuilder("example-0dhg9709da.7d67968.vpc-lattice-svcs.eu-central-1.on.aws", newVPCLatticeV1Request)
+}
+
+// VPCLatticeV2 creates a builder for events handled by handler.NewVPCLatticeV2Handler
+func VPCLatticeV2() *Builder[handler.VPCLatticeV2Request] {
+	return newBuilder("example-0dhg9709da.7d67968.vpc-lattice-svcs.eu-central-1.on.aws", newVPCLatticeV2Request)
+}
+
+func newVPCLatticeV1Request(r *http.Request) (handler.VPCLatticeV1Request, error) {
+	body, isB64, err := readBody(r)
+	if err != nil {
+		return handler.VPCLatticeV1Request{}, err
+	}
+
+	event := handler.VPCLatticeV1Request{
+		RawPath:               r.URL.RequestURI(),
+		Method:                r.Method,
+		Headers:               make(map[string]string),
+		QueryStringParameters: make(map[string]string),
+		Body:                  body,
+		IsBase64Encoded:       isB64,
+	}
+
+	for k, values := range forwardedHeaders(r) {
+		event.Headers[strings.ToLower(k)] = strings.Join(values, ",")
+	}
+
+	for k, values := range r.URL.Query() {
+		event.QueryStringParameters[k] = values[len(values)-1]
+	}
+
+	return event, nil
+}
+
+func newVPCLatticeV2Request(r *http.Request) (handler.VPCLatticeV2Request, error) {
+	body, isB64, err := readBody(r)
+	if err != nil {
+		return handler.VPCLatticeV2Request{}, err
+	}
+
+	event := handler.VPCLatticeV2Request{
+		Version:               "2.0",
+		Path:                  r.URL.RequestURI(),
+		Method:                r.Method,
+		Headers:               make(map[string][]string),
+		QueryStringParameters: make(map[string][]string),
+		Body:                  body,
+		IsBase64Encoded:       isB64,
+		RequestContext: handler.VPCLatticeRequestContext{
+			ServiceNetworkARN: "arn:aws:vpc-lattice:eu-central-1:123456789012:servicenetwork/sn-0dhg9709da",
+			ServiceARN:        "arn:aws:vpc-lattice:eu-central-1:123456789012:service/svc-0dhg9709da",
+			TargetGroupARN:    "arn:aws:vpc-lattice:eu-central-1:123456789012:targetgroup/tg-0dhg9709da",
+			Identity: handler.VPCLatticeIdentity{
+				SourceVPCARN: "arn:aws:ec2:eu-central-1:123456789012:vpc/vpc-0dhg9709da",
+				Type:         "AWS_IAM",
+			},
+			Region:    "eu-central-1",
+			TimeEpoch: strconv.FormatInt(time.Now().UnixMicro(), 10),
+		},
+	}
+
+	for k, values := range forwardedHeaders(r) {
+		event.Headers[strings.ToLower(k)] = values
+	}
+
+	for k, values := range r.URL.Query() {
+		event.QueryStringParameters[k] = values
+	}
+
+	return event, nil
+}
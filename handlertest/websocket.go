@@ -0,0 +1,82 @@
+//go:build !lambdahttpadapter.partial || (lambdahttpadapter.partial && lambdahttpadapter.websocket)
+
+package handlertest
+
+import (
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/its-felix/aws-lambda-go-http-adapter/handler"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Websocket creates a builder for events handled by handler.NewWebsocketHandler.
+// The route key is taken from the path, for example POST("/$connect").
+func Websocket() *Builder[events.APIGatewayWebsocketProxyRequest] {
+	return newBuilder("0dhg9709da.execute-api.eu-central-1.amazonaws.com", newWebsocketRequest)
+}
+
+func newWebsocketRequest(r *http.Request) (events.APIGatewayWebsocketProxyRequest, error) {
+	body, isB64, err := readBody(r)
+	if err != nil {
+		return events.APIGatewayWebsocketProxyRequest{}, err
+	}
+
+	routeKey, err := url.PathUnescape(strings.TrimPrefix(r.URL.EscapedPath(), "/"))
+	if err != nil {
+		return events.APIGatewayWebsocketProxyRequest{}, err
+	}
+
+	eventType := "MESSAGE"
+	switch routeKey {
+	case "$connect":
+		eventType = "CONNECT"
+	case "$disconnect":
+		eventType = "DISCONNECT"
+	}
+
+	connectionID := r.Header.Get(handler.HeaderWebsocketConnectionID)
+	if connectionID == "" {
+		connectionID = "L0SM9cOFvHcCIhw="
+	}
+
+	event := events.APIGatewayWebsocketProxyRequest{
+		Headers:                         make(map[string]string),
+		MultiValueHeaders:               make(map[string][]string),
+		QueryStringParameters:           make(map[string]string),
+		MultiValueQueryStringParameters: make(map[string][]string),
+		RequestContext: events.APIGatewayWebsocketProxyRequestContext{
+			RouteKey:     routeKey,
+			EventType:    eventType,
+			ConnectionID: connectionID,
+			DomainName:   r.Host,
+			Stage:        "production",
+			APIID:        "0dhg9709da",
+			Identity: events.APIGatewayRequestIdentity{
+				SourceIP: sourceIP(r),
+			},
+		},
+		Body:            body,
+		IsBase64Encoded: isB64,
+	}
+
+	if eventType == "MESSAGE" {
+		event.RequestContext.MessageID = "f2sCMc2Pli0CFhw="
+	}
+
+	headers := r.Header.Clone()
+	headers.Set("Host", r.Host)
+	headers.Del(handler.HeaderWebsocketConnectionID)
+
+	for k, values := range headers {
+		event.Headers[k] = values[len(values)-1]
+		event.MultiValueHeaders[k] = values
+	}
+
+	for k, values := range r.URL.Query() {
+		event.QueryStringParameters[k] = values[len(values)-1]
+		event.MultiValueQueryStringParameters[k] = values
+	}
+
+	return event, nil
+}
@@ -0,0 +1,255 @@
+package local
+
+import (
+	"github.com/aws/aws-lambda-go/events"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// FunctionURLFormat is the Format of handlers created using handler.NewFunctionURLHandler
+var FunctionURLFormat = Format[events.LambdaFunctionURLRequest, events.LambdaFunctionURLResponse]{
+	NewEvent:      newFunctionURLRequest,
+	WriteResponse: writeFunctionURLResponse,
+}
+
+// FunctionURLStreamingFormat is the Format of handlers created using handler.NewFunctionURLStreamingHandler.
+// The response body is relayed to the client as it arrives.
+var FunctionURLStreamingFormat = Format[events.LambdaFunctionURLRequest, *events.LambdaFunctionURLStreamingResponse]{
+	NewEvent:      newFunctionURLRequest,
+	WriteResponse: writeFunctionURLStreamingResponse,
+}
+
+// APIGatewayV1Format is the Format of handlers created using handler.NewAPIGatewayV1Handler
+var APIGatewayV1Format = Format[events.APIGatewayProxyRequest, events.APIGatewayProxyResponse]{
+	NewEvent:      newAPIGatewayV1Request,
+	WriteResponse: writeAPIGatewayV1Response,
+}
+
+// APIGatewayV2Format is the Format of handlers created using handler.NewAPIGatewayV2Handler
+var APIGatewayV2Format = Format[events.APIGatewayV2HTTPRequest, events.APIGatewayV2HTTPResponse]{
+	NewEvent:      newAPIGatewayV2Request,
+	WriteResponse: writeAPIGatewayV2Response,
+}
+
+// singleValueHeaders returns the headers like Function URLs and API Gateway V2 do: lowercase, multiple values joined by a comma and without cookies
+func singleValueHeaders(r *http.Request) map[string]string {
+	headers := make(map[string]string)
+	for k, values := range r.Header {
+		if strings.EqualFold("cookie", k) {
+			continue
+		}
+
+		headers[strings.ToLower(k)] = strings.Join(values, ",")
+	}
+
+	headers["host"] = r.Host
+
+	return headers
+}
+
+func singleValueQuery(r *http.Request) map[string]string {
+	q := make(map[string]string)
+	for k, values := range r.URL.Query() {
+		q[k] = strings.Join(values, ",")
+	}
+
+	return q
+}
+
+func newFunctionURLRequest(r *http.Request) (events.LambdaFunctionURLRequest, error) {
+	body, isB64, err := readBody(r)
+	if err != nil {
+		return events.LambdaFunctionURLRequest{}, err
+	}
+
+	t, epoch := requestTime()
+
+	return events.LambdaFunctionURLRequest{
+		Version:               "2.0",
+		RawPath:               r.URL.EscapedPath(),
+		RawQueryString:        r.URL.RawQuery,
+		Cookies:               cookies(r),
+		Headers:               singleValueHeaders(r),
+		QueryStringParameters: singleValueQuery(r),
+		RequestContext: events.LambdaFunctionURLRequestContext{
+			AccountID:    "anonymous",
+			RequestID:    requestID(r),
+			APIID:        domainPrefix(r.Host),
+			DomainName:   r.Host,
+			DomainPrefix: domainPrefix(r.Host),
+			Time:         t,
+			TimeEpoch:    epoch,
+			HTTP: events.LambdaFunctionURLRequestContextHTTPDescription{
+				Method:    r.Method,
+				Path:      r.URL.Path,
+				Protocol:  r.Proto,
+				SourceIP:  sourceIP(r),
+				UserAgent: r.UserAgent(),
+			},
+		},
+		Body:            body,
+		IsBase64Encoded: isB64,
+	}, nil
+}
+
+func writeFunctionURLResponse(w http.ResponseWriter, res events.LambdaFunctionURLResponse) error {
+	writeHeaders(w, res.Headers, nil, res.Cookies)
+	w.WriteHeader(statusCode(res.StatusCode))
+
+	return writeBody(w, res.Body, res.IsBase64Encoded)
+}
+
+func writeFunctionURLStreamingResponse(w http.ResponseWriter, res *events.LambdaFunctionURLStreamingResponse) error {
+	if res == nil {
+		http.Error(w, `{"message":"Internal Server Error"}`, http.StatusBadGateway)
+		return nil
+	}
+
+	writeHeaders(w, res.Headers, nil, res.Cookies)
+	w.WriteHeader(statusCode(res.StatusCode))
+
+	if res.Body == nil {
+		return nil
+	}
+
+	if closer, ok := res.Body.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	flusher, _ := w.(http.Flusher)
+	if flusher != nil {
+		flusher.Flush()
+	}
+
+	buf := make([]byte, 4096)
+	for {
+		n, err := res.Body.Read(buf)
+		if n > 0 {
+			if _, wErr := w.Write(buf[:n]); wErr != nil {
+				return wErr
+			}
+
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+func newAPIGatewayV1Request(r *http.Request) (events.APIGatewayProxyRequest, error) {
+	body, isB64, err := readBody(r)
+	if err != nil {
+		return events.APIGatewayProxyRequest{}, err
+	}
+
+	headers := make(map[string]string)
+	multiValueHeaders := make(map[string][]string)
+	for k, values := range r.Header {
+		headers[k] = values[len(values)-1]
+		multiValueHeaders[k] = values
+	}
+
+	headers["Host"] = r.Host
+	multiValueHeaders["Host"] = []string{r.Host}
+
+	q := make(map[string]string)
+	multiValueQ := make(map[string][]string)
+	for k, values := range r.URL.Query() {
+		q[k] = values[len(values)-1]
+		multiValueQ[k] = values
+	}
+
+	t, epoch := requestTime()
+
+	return events.APIGatewayProxyRequest{
+		Resource:                        "/{proxy+}",
+		Path:                            r.URL.Path,
+		HTTPMethod:                      r.Method,
+		Headers:                         headers,
+		MultiValueHeaders:               multiValueHeaders,
+		QueryStringParameters:           q,
+		MultiValueQueryStringParameters: multiValueQ,
+		PathParameters:                  map[string]string{"proxy": strings.TrimPrefix(r.URL.Path, "/")},
+		RequestContext: events.APIGatewayProxyRequestContext{
+			AccountID:         "anonymous",
+			ResourcePath:      "/{proxy+}",
+			Stage:             "local",
+			DomainName:        r.Host,
+			DomainPrefix:      domainPrefix(r.Host),
+			RequestID:         requestID(r),
+			ExtendedRequestID: requestID(r),
+			Protocol:          r.Proto,
+			Identity: events.APIGatewayRequestIdentity{
+				SourceIP:  sourceIP(r),
+				UserAgent: r.UserAgent(),
+			},
+			HTTPMethod:       r.Method,
+			APIID:            domainPrefix(r.Host),
+			Path:             r.URL.Path,
+			RequestTime:      t,
+			RequestTimeEpoch: epoch,
+		},
+		Body:            body,
+		IsBase64Encoded: isB64,
+	}, nil
+}
+
+func writeAPIGatewayV1Response(w http.ResponseWriter, res events.APIGatewayProxyResponse) error {
+	writeHeaders(w, res.Headers, res.MultiValueHeaders, nil)
+	w.WriteHeader(statusCode(res.StatusCode))
+
+	return writeBody(w, res.Body, res.IsBase64Encoded)
+}
+
+func newAPIGatewayV2Request(r *http.Request) (events.APIGatewayV2HTTPRequest, error) {
+	body, isB64, err := readBody(r)
+	if err != nil {
+		return events.APIGatewayV2HTTPRequest{}, err
+	}
+
+	t, epoch := requestTime()
+
+	return events.APIGatewayV2HTTPRequest{
+		Version:               "2.0",
+		RouteKey:              "$default",
+		RawPath:               r.URL.EscapedPath(),
+		RawQueryString:        r.URL.RawQuery,
+		Cookies:               cookies(r),
+		Headers:               singleValueHeaders(r),
+		QueryStringParameters: singleValueQuery(r),
+		RequestContext: events.APIGatewayV2HTTPRequestContext{
+			RouteKey:     "$default",
+			AccountID:    "anonymous",
+			Stage:        "$default",
+			RequestID:    requestID(r),
+			APIID:        domainPrefix(r.Host),
+			DomainName:   r.Host,
+			DomainPrefix: domainPrefix(r.Host),
+			Time:         t,
+			TimeEpoch:    epoch,
+			HTTP: events.APIGatewayV2HTTPRequestContextHTTPDescription{
+				Method:    r.Method,
+				Path:      r.URL.Path,
+				Protocol:  r.Proto,
+				SourceIP:  sourceIP(r),
+				UserAgent: r.UserAgent(),
+			},
+		},
+		Body:            body,
+		IsBase64Encoded: isB64,
+	}, nil
+}
+
+func writeAPIGatewayV2Response(w http.ResponseWriter, res events.APIGatewayV2HTTPResponse) error {
+	writeHeaders(w, res.Headers, res.MultiValueHeaders, res.Cookies)
+	w.WriteHeader(statusCode(res.StatusCode))
+
+	return writeBody(w, res.Body, res.IsBase64Encoded)
+}
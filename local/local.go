@@ -0,0 +1,179 @@
+// Package local runs Lambda handlers created by this library as a regular HTTP server for local development.
+// Each incoming request is converted into a Lambda event, passed to the handler, and the resulting response is written back.
+package local
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Format converts HTTP requests into Lambda events of type In and responses of type Out back into HTTP responses
+type Format[In any, Out any] struct {
+	NewEvent      func(r *http.Request) (In, error)
+	WriteResponse func(w http.ResponseWriter, res Out) error
+}
+
+type localHandler[In any, Out any] struct {
+	handler func(context.Context, In) (Out, error)
+	format  Format[In, Out]
+}
+
+func (h localHandler[In, Out]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id := newRequestID()
+	r = r.WithContext(context.WithValue(r.Context(), requestIDContextKey{}, id))
+
+	event, err := h.format.NewEvent(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx := lambdacontext.NewContext(r.Context(), &lambdacontext.LambdaContext{
+		AwsRequestID: id,
+	})
+
+	res, err := h.handler(ctx, event)
+	if err != nil {
+		// this mirrors the behavior of Lambda Function URLs and API Gateway if the invocation fails
+		log.Printf("local: handler failed: %v", err)
+		http.Error(w, `{"message":"Internal Server Error"}`, http.StatusBadGateway)
+		return
+	}
+
+	if err = h.format.WriteResponse(w, res); err != nil {
+		log.Printf("local: failed to write response: %v", err)
+	}
+}
+
+// NewHandler creates a http.Handler which invokes the given Lambda handler for each request
+func NewHandler[In any, Out any](handler func(context.Context, In) (Out, error), format Format[In, Out]) http.Handler {
+	return localHandler[In, Out]{handler, format}
+}
+
+// ListenAndServe listens on the TCP network address addr and invokes the given Lambda handler for each request
+func ListenAndServe[In any, Out any](addr string, handler func(context.Context, In) (Out, error), format Format[In, Out]) error {
+	return http.ListenAndServe(addr, NewHandler(handler, format))
+}
+
+type requestIDContextKey struct{}
+
+func requestID(r *http.Request) string {
+	if id, ok := r.Context().Value(requestIDContextKey{}).(string); ok {
+		return id
+	}
+
+	return newRequestID()
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+func readBody(r *http.Request) (string, bool, error) {
+	if r.Body == nil {
+		return "", false, nil
+	}
+
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return "", false, err
+	}
+
+	if len(b) == 0 {
+		return "", false, nil
+	} else if utf8.Valid(b) {
+		return string(b), false, nil
+	}
+
+	return base64.StdEncoding.EncodeToString(b), true, nil
+}
+
+func writeBody(w http.ResponseWriter, body string, isB64 bool) error {
+	var b []byte
+	if isB64 {
+		var err error
+		if b, err = base64.StdEncoding.DecodeString(body); err != nil {
+			return err
+		}
+	} else {
+		b = []byte(body)
+	}
+
+	_, err := w.Write(b)
+	return err
+}
+
+func sourceIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+func domainPrefix(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	prefix, _, _ := strings.Cut(host, ".")
+	return prefix
+}
+
+func cookies(r *http.Request) []string {
+	result := make([]string, 0)
+	for _, v := range r.Header.Values("Cookie") {
+		for _, c := range strings.Split(v, ";") {
+			if c = strings.TrimSpace(c); c != "" {
+				result = append(result, c)
+			}
+		}
+	}
+
+	return result
+}
+
+func requestTime() (string, int64) {
+	now := time.Now().UTC()
+	return now.Format("02/Jan/2006:15:04:05 -0700"), now.UnixMilli()
+}
+
+func writeHeaders(w http.ResponseWriter, headers map[string]string, multiValueHeaders map[string][]string, cookies []string) {
+	for k, v := range headers {
+		w.Header().Set(k, v)
+	}
+
+	for k, values := range multiValueHeaders {
+		w.Header().Del(k)
+
+		for _, v := range values {
+			w.Header().Add(k, v)
+		}
+	}
+
+	for _, v := range cookies {
+		w.Header().Add("Set-Cookie", v)
+	}
+}
+
+func statusCode(statusCode int) int {
+	if statusCode == 0 {
+		return http.StatusOK
+	}
+
+	return statusCode
+}
@@ -0,0 +1,150 @@
+package local
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/its-felix/aws-lambda-go-http-adapter/adapter"
+	"github.com/its-felix/aws-lambda-go-http-adapter/handler"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newEchoMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		c, _ := r.Cookie("session")
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Add("X-Example", "a")
+		w.Header().Add("X-Example", "b")
+		http.SetCookie(w, &http.Cookie{Name: "example", Value: "value"})
+		w.WriteHeader(http.StatusCreated)
+
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"Method": r.Method,
+			"URL":    r.URL.String(),
+			"Body":   hex.EncodeToString(b),
+			"Cookie": c.String(),
+		})
+	})
+
+	return mux
+}
+
+func assertResponse(t *testing.T, res *http.Response, expectedURL string) {
+	t.Helper()
+
+	if res.StatusCode != http.StatusCreated {
+		t.Errorf("unexpected status: %v", res.StatusCode)
+	}
+
+	if res.Header.Get("Set-Cookie") != "example=value" {
+		t.Errorf("unexpected Set-Cookie header: %v", res.Header.Values("Set-Cookie"))
+	}
+
+	var result map[string]string
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		t.Fatal(err)
+	}
+
+	if result["Method"] != http.MethodPost || result["URL"] != expectedURL || result["Body"] != "0001ff" || result["Cookie"] != "session=abc" {
+		t.Errorf("unexpected request: %v", result)
+	}
+}
+
+func doRequest(t *testing.T, h http.Handler) (*http.Response, string) {
+	t.Helper()
+
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/example/path?key=value&key=other", strings.NewReader("\x00\x01\xff"))
+	req.AddCookie(&http.Cookie{Name: "session", Value: "abc"})
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = res.Body.Close() })
+
+	return res, "https://" + strings.TrimPrefix(srv.URL, "http://")
+}
+
+func TestFunctionURLFormat(t *testing.T) {
+	res, base := doRequest(t, NewHandler(handler.NewFunctionURLHandler(adapter.NewVanillaAdapter(newEchoMux())), FunctionURLFormat))
+	assertResponse(t, res, base+"/example/path?key=value&key=other")
+
+	if res.Header.Get("X-Example") != "a,b" {
+		t.Errorf("unexpected X-Example header: %v", res.Header.Values("X-Example"))
+	}
+}
+
+func TestAPIGatewayV1Format(t *testing.T) {
+	res, base := doRequest(t, NewHandler(handler.NewAPIGatewayV1Handler(adapter.NewVanillaAdapter(newEchoMux())), APIGatewayV1Format))
+	assertResponse(t, res, base+"/example/path?key=value&key=other")
+
+	if v := res.Header.Values("X-Example"); len(v) != 2 {
+		t.Errorf("unexpected X-Example header: %v", v)
+	}
+}
+
+func TestAPIGatewayV2Format(t *testing.T) {
+	res, base := doRequest(t, NewHandler(handler.NewAPIGatewayV2Handler(adapter.NewVanillaAdapter(newEchoMux())), APIGatewayV2Format))
+	assertResponse(t, res, base+"/example/path?key=value&key=other")
+}
+
+func TestFunctionURLStreamingFormat(t *testing.T) {
+	res, base := doRequest(t, NewHandler(handler.NewFunctionURLStreamingHandler(adapter.NewVanillaAdapter(newEchoMux())), FunctionURLStreamingFormat))
+	assertResponse(t, res, base+"/example/path?key=value&key=other")
+}
+
+func TestFunctionURLStreamingFormatRelaysChunks(t *testing.T) {
+	next := make(chan struct{})
+	h := handler.NewFunctionURLStreamingHandler(func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+		_, _ = w.Write([]byte("first\n"))
+		<-next
+		_, _ = w.Write([]byte("second\n"))
+		return nil
+	})
+
+	srv := httptest.NewServer(NewHandler(h, FunctionURLStreamingFormat))
+	defer srv.Close()
+
+	res, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer res.Body.Close()
+
+	r := bufio.NewReader(res.Body)
+	if line, err := r.ReadString('\n'); err != nil || line != "first\n" {
+		t.Fatalf("expected the first chunk to arrive before the second was written, got %q (%v)", line, err)
+	}
+
+	close(next)
+
+	if line, err := r.ReadString('\n'); err != nil || line != "second\n" {
+		t.Errorf("unexpected second chunk: %q (%v)", line, err)
+	}
+}
+
+func TestHandlerError(t *testing.T) {
+	h := handler.NewFunctionURLHandler(func(ctx context.Context, r *http.Request, w http.ResponseWriter) error {
+		return context.Canceled
+	})
+
+	rec := httptest.NewRecorder()
+	NewHandler(h, FunctionURLFormat).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusBadGateway {
+		t.Errorf("unexpected status: %v", rec.Code)
+	}
+}